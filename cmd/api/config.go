@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/handler"
+	"github.com/thihxm/ebanx-home-assignment/internal/ratelimit"
+)
+
+// config is read from flags, falling back to environment variables and then
+// to built-in defaults.
+type config struct {
+	Addr            string
+	Environment     string
+	Storage         string
+	LogLevel        slog.Level
+	LogMask         bool
+	OutboxURL       string
+	OutboxFile      string
+	Tracing         string
+	APIKeysFile     string
+	JWT             auth.JWTConfig
+	PartnersFile    string
+	SignatureWindow time.Duration
+	ClientRate      ratelimit.Config
+	AccountRate     ratelimit.Config
+	Risk            riskConfig
+	ScreeningFile   string
+	ScreeningReload time.Duration
+	AuditFile       string
+	Server          handler.ServerConfig
+}
+
+// riskConfig selects the risk rules. Each rule is off while its threshold
+// is zero.
+type riskConfig struct {
+	Action                  string
+	VelocityCount           int
+	VelocityWindow          time.Duration
+	AnomalyMultiplier       float64
+	AnomalyMinHistory       int
+	NewDestinationCooldown  time.Duration
+	NewDestinationMinAmount int
+	Blocklist               string
+}
+
+func (c config) production() bool {
+	return c.Environment == "production"
+}
+
+func loadConfig(args []string) (config, error) {
+	defaults := handler.DefaultServerConfig()
+	cfg := config{}
+	var logLevel string
+
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.StringVar(&cfg.Addr, "addr", envString("ADDR", ":8080"), "listen address (env ADDR)")
+	fs.StringVar(&cfg.Environment, "environment", envString("ENVIRONMENT", "development"), "development or production; production disables /reset and requires authentication (env ENVIRONMENT)")
+	fs.StringVar(&cfg.APIKeysFile, "api-keys-file", envString("API_KEYS_FILE", ""), "JSON file of hashed API keys; enables authentication (env API_KEYS_FILE)")
+	fs.StringVar(&cfg.JWT.JWKSFile, "jwks-file", envString("JWKS_FILE", ""), "JWKS file for verifying bearer tokens; enables JWT authentication (env JWKS_FILE)")
+	fs.StringVar(&cfg.JWT.Issuer, "jwt-issuer", envString("JWT_ISSUER", ""), "required iss claim (env JWT_ISSUER)")
+	fs.StringVar(&cfg.JWT.Audience, "jwt-audience", envString("JWT_AUDIENCE", ""), "required aud claim (env JWT_AUDIENCE)")
+	fs.DurationVar(&cfg.JWT.ClockSkew, "jwt-clock-skew", envDuration("JWT_CLOCK_SKEW", 30*time.Second), "leeway for exp and nbf (env JWT_CLOCK_SKEW)")
+	fs.StringVar(&cfg.PartnersFile, "partners-file", envString("PARTNERS_FILE", ""), "JSON file of partner HMAC secrets; enables request signing (env PARTNERS_FILE)")
+	fs.DurationVar(&cfg.SignatureWindow, "signature-window", envDuration("SIGNATURE_WINDOW", 5*time.Minute), "accepted clock difference for signed requests (env SIGNATURE_WINDOW)")
+	fs.Float64Var(&cfg.ClientRate.Rate, "client-rate", envFloat("CLIENT_RATE", 50), "requests per second per client; 0 disables (env CLIENT_RATE)")
+	fs.IntVar(&cfg.ClientRate.Burst, "client-burst", envInt("CLIENT_BURST", 100), "env CLIENT_BURST")
+	fs.Float64Var(&cfg.AccountRate.Rate, "account-rate", envFloat("ACCOUNT_RATE", 5), "withdrawals and transfers per second per origin account; 0 disables (env ACCOUNT_RATE)")
+	fs.IntVar(&cfg.AccountRate.Burst, "account-burst", envInt("ACCOUNT_BURST", 20), "env ACCOUNT_BURST")
+	fs.StringVar(&cfg.Risk.Action, "risk-action", envString("RISK_ACTION", "review"), "what velocity, anomaly and new-destination rules do: review or deny (env RISK_ACTION)")
+	fs.IntVar(&cfg.Risk.VelocityCount, "risk-velocity-count", envInt("RISK_VELOCITY_COUNT", 0), "debits per account allowed within the velocity window; 0 disables (env RISK_VELOCITY_COUNT)")
+	fs.DurationVar(&cfg.Risk.VelocityWindow, "risk-velocity-window", envDuration("RISK_VELOCITY_WINDOW", 10*time.Minute), "env RISK_VELOCITY_WINDOW")
+	fs.Float64Var(&cfg.Risk.AnomalyMultiplier, "risk-anomaly-multiplier", envFloat("RISK_ANOMALY_MULTIPLIER", 0), "flag debits above this multiple of the account's average; 0 disables (env RISK_ANOMALY_MULTIPLIER)")
+	fs.IntVar(&cfg.Risk.AnomalyMinHistory, "risk-anomaly-min-history", envInt("RISK_ANOMALY_MIN_HISTORY", 5), "debits needed before the anomaly rule applies (env RISK_ANOMALY_MIN_HISTORY)")
+	fs.DurationVar(&cfg.Risk.NewDestinationCooldown, "risk-new-destination-cooldown", envDuration("RISK_NEW_DESTINATION_COOLDOWN", 0), "flag transfers to destinations first paid within this period; 0 disables (env RISK_NEW_DESTINATION_COOLDOWN)")
+	fs.IntVar(&cfg.Risk.NewDestinationMinAmount, "risk-new-destination-min-amount", envInt("RISK_NEW_DESTINATION_MIN_AMOUNT", 0), "transfers at or below this amount skip the cooldown (env RISK_NEW_DESTINATION_MIN_AMOUNT)")
+	fs.StringVar(&cfg.Risk.Blocklist, "risk-blocklist", envString("RISK_BLOCKLIST", ""), "comma-separated accounts whose withdrawals and transfers are denied (env RISK_BLOCKLIST)")
+	fs.StringVar(&cfg.ScreeningFile, "screening-file", envString("SCREENING_FILE", ""), "CSV or JSON sanctions list; events involving listed accounts are blocked (env SCREENING_FILE)")
+	fs.DurationVar(&cfg.ScreeningReload, "screening-reload-interval", envDuration("SCREENING_RELOAD_INTERVAL", 10*time.Second), "how often the screening file is checked for changes; 0 disables reloading (env SCREENING_RELOAD_INTERVAL)")
+	fs.StringVar(&cfg.AuditFile, "audit-file", envString("AUDIT_FILE", ""), "append the audit log to this file; kept in memory only when empty (env AUDIT_FILE)")
+	fs.StringVar(&cfg.Storage, "storage", envString("STORAGE", "memory"), "storage backend: memory (env STORAGE)")
+	fs.StringVar(&logLevel, "log-level", envString("LOG_LEVEL", "info"), "log level: debug, info, warn, error (env LOG_LEVEL)")
+	fs.BoolVar(&cfg.LogMask, "log-mask-accounts", envBool("LOG_MASK_ACCOUNTS", false), "mask account IDs in logs (env LOG_MASK_ACCOUNTS)")
+	fs.StringVar(&cfg.OutboxURL, "outbox-url", envString("OUTBOX_URL", ""), "publish outbox messages to this HTTP sink (env OUTBOX_URL)")
+	fs.StringVar(&cfg.OutboxFile, "outbox-file", envString("OUTBOX_FILE", ""), "append outbox messages to this file (env OUTBOX_FILE)")
+	fs.StringVar(&cfg.Tracing, "trace-exporter", envString("TRACE_EXPORTER", "none"), "trace exporter: none, stdout (env TRACE_EXPORTER)")
+	fs.DurationVar(&cfg.Server.ReadHeaderTimeout, "read-header-timeout", envDuration("READ_HEADER_TIMEOUT", defaults.ReadHeaderTimeout), "env READ_HEADER_TIMEOUT")
+	fs.DurationVar(&cfg.Server.ReadTimeout, "read-timeout", envDuration("READ_TIMEOUT", defaults.ReadTimeout), "env READ_TIMEOUT")
+	fs.DurationVar(&cfg.Server.WriteTimeout, "write-timeout", envDuration("WRITE_TIMEOUT", defaults.WriteTimeout), "env WRITE_TIMEOUT")
+	fs.DurationVar(&cfg.Server.IdleTimeout, "idle-timeout", envDuration("IDLE_TIMEOUT", defaults.IdleTimeout), "env IDLE_TIMEOUT")
+	fs.DurationVar(&cfg.Server.ShutdownTimeout, "shutdown-timeout", envDuration("SHUTDOWN_TIMEOUT", defaults.ShutdownTimeout), "time allowed for draining requests on shutdown (env SHUTDOWN_TIMEOUT)")
+	fs.DurationVar(&cfg.Server.RequestTimeout, "request-timeout", envDuration("REQUEST_TIMEOUT", defaults.RequestTimeout), "deadline for handling a single request; 0 disables (env REQUEST_TIMEOUT)")
+	fs.IntVar(&cfg.Server.MaxHeaderBytes, "max-header-bytes", envInt("MAX_HEADER_BYTES", defaults.MaxHeaderBytes), "env MAX_HEADER_BYTES")
+	fs.Int64Var(&cfg.Server.MaxBodyBytes, "max-body-bytes", int64(envInt("MAX_BODY_BYTES", int(defaults.MaxBodyBytes))), "env MAX_BODY_BYTES")
+	fs.Int64Var(&cfg.Server.MaxBulkBodyBytes, "max-bulk-body-bytes", int64(envInt("MAX_BULK_BODY_BYTES", int(defaults.MaxBulkBodyBytes))), "body limit for /admin/import and /admin/restore (env MAX_BULK_BODY_BYTES)")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if err := cfg.LogLevel.UnmarshalText([]byte(logLevel)); err != nil {
+		return cfg, fmt.Errorf("invalid log level %q", logLevel)
+	}
+	if cfg.Environment != "development" && cfg.Environment != "production" {
+		return cfg, fmt.Errorf("invalid environment %q", cfg.Environment)
+	}
+	if cfg.production() && cfg.APIKeysFile == "" && cfg.JWT.JWKSFile == "" && cfg.PartnersFile == "" {
+		return cfg, fmt.Errorf("production requires -api-keys-file, -jwks-file or -partners-file")
+	}
+	if cfg.Storage != "memory" {
+		return cfg, fmt.Errorf("unsupported storage backend %q", cfg.Storage)
+	}
+	return cfg, nil
+}
+
+func envString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func envBool(key string, def bool) bool {
+	if v, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if v, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if v, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
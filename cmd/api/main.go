@@ -1,21 +1,232 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/thihxm/ebanx-home-assignment/internal/audit"
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
 	"github.com/thihxm/ebanx-home-assignment/internal/handler"
+	"github.com/thihxm/ebanx-home-assignment/internal/health"
+	"github.com/thihxm/ebanx-home-assignment/internal/logging"
+	"github.com/thihxm/ebanx-home-assignment/internal/metrics"
+	"github.com/thihxm/ebanx-home-assignment/internal/outbox"
+	"github.com/thihxm/ebanx-home-assignment/internal/ratelimit"
 	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/risk"
+	"github.com/thihxm/ebanx-home-assignment/internal/screening"
 	"github.com/thihxm/ebanx-home-assignment/internal/service"
+	"github.com/thihxm/ebanx-home-assignment/internal/tracing"
 )
 
 func main() {
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		slog.Error("Error loading config", "error", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logging.New(os.Stderr, logging.Options{
+		Level:        cfg.LogLevel,
+		MaskAccounts: cfg.LogMask,
+	}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		slog.Error("Error serving HTTP server", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Server stopped")
+}
+
+func run(ctx context.Context, cfg config) error {
+	registry := health.NewRegistry()
+	// Startup stays unready until the dependencies below are wired, which
+	// for persistent backends includes replaying their logs.
+	startup := health.NewGate("starting up")
+	registry.Register("startup", startup.Check)
+
+	tp, shutdownTracing, err := tracing.NewProvider(cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
+	m := metrics.New()
 	repo := repository.NewInMemoryRepository()
-	accountService := service.NewAccountService(repo)
-	eventService := service.NewEventService(accountService)
+	registry.Register("repository", repo.Ping)
+	m.RegisterAccountCount(repo.Count)
+
+	auditLog, err := newAuditLog(cfg)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+
+	accountOpts := []service.AccountServiceOption{service.WithAuditLog(auditLog)}
+	publisher, err := newOutboxPublisher(cfg)
+	if err != nil {
+		return err
+	}
+	if publisher != nil {
+		accountOpts = append(accountOpts, service.WithOutbox())
+		relay := outbox.NewRelay(repo, publisher, outbox.DefaultRelayConfig())
+		relay.Start()
+		registry.Register("outbox", relay.Check)
+		// Deferred calls run after the server has drained, so events from the
+		// last requests are still relayed.
+		defer relay.Stop()
+	}
+
+	var eventOpts []service.EventServiceOption
+	if cfg.ScreeningFile != "" {
+		list, err := screening.LoadList(cfg.ScreeningFile, cfg.ScreeningReload, screening.WithAuditLog(auditLog))
+		if err != nil {
+			return err
+		}
+		eventOpts = append(eventOpts, service.WithScreener(list))
+		accountOpts = append(accountOpts, service.WithImportScreener(list))
+	}
+	instrumentedRepo := tracing.NewAccountRepository(metrics.NewAccountRepository(repo, m), tp)
+	baseAccountService := service.NewAccountService(instrumentedRepo, accountOpts...)
+	accountService := metrics.NewAccountService(baseAccountService, m)
+	eventService := service.NewEventService(accountService, eventOpts...)
+	webhookService := service.NewWebhookService(service.DefaultWebhookConfig())
+	defer webhookService.Close()
+	registry.Register("webhooks", webhookService.Check)
+
+	ledgerService := service.NewLedgerService(repo)
+	handlerOpts := []handler.Option{
+		handler.WithWebhookService(webhookService),
+		handler.WithAuditLog(auditLog),
+		handler.WithLedgerService(ledgerService),
+		handler.WithImporter(baseAccountService),
+		handler.WithExporter(ledgerService),
+		handler.WithSnapshotService(service.NewSnapshotService(repo, service.WithSnapshotAuditLog(auditLog))),
+		handler.WithServerConfig(cfg.Server),
+		handler.WithHealthRegistry(registry),
+		handler.WithMetrics(m),
+		handler.WithTracing(tp),
+		handler.WithClientRateLimit(ratelimit.NewLimiter(cfg.ClientRate)),
+		handler.WithAccountRateLimit(ratelimit.NewLimiter(cfg.AccountRate)),
+	}
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+	if authenticator != nil {
+		handlerOpts = append(handlerOpts, handler.WithAuthenticator(authenticator))
+	}
+	if cfg.production() {
+		handlerOpts = append(handlerOpts, handler.WithProductionMode())
+	}
+	var screened domain.EventService = eventService
+	rules, err := newRiskRules(cfg.Risk)
+	if err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		riskCfg := risk.DefaultConfig()
+		riskCfg.Rules = rules
+		riskCfg.Audit = auditLog
+		riskService := risk.NewService(eventService, riskCfg)
+		eventService.AddListener(riskService)
+		handlerOpts = append(handlerOpts, handler.WithReviewService(riskService))
+		screened = riskService
+	}
+	httpHandler := handler.NewAccountHTTPHandler(accountService, tracing.NewEventService(screened, tp), handlerOpts...)
+	eventService.AddListener(httpHandler)
+	eventService.AddListener(webhookService)
+
+	startup.Open()
 
-	httpHandler := handler.NewAccountHTTPHandler(accountService, eventService)
+	return httpHandler.Serve(ctx, cfg.Addr)
+}
 
-	if err := httpHandler.Serve(":8080"); err != nil {
-		log.Fatalf("Error serving HTTP server: %v", err)
+// newAuthenticator accepts every configured credential type. It returns nil
+// when none is configured, leaving authentication disabled.
+func newAuthenticator(cfg config) (auth.Authenticator, error) {
+	var chain auth.Chain
+	if cfg.APIKeysFile != "" {
+		keys, err := auth.LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+	if cfg.JWT.JWKSFile != "" {
+		jwt, err := auth.NewJWT(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwt)
+	}
+	if cfg.PartnersFile != "" {
+		signatures, err := auth.LoadSignatures(cfg.PartnersFile, cfg.SignatureWindow)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, signatures)
+	}
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// newRiskRules builds the configured risk rules. It returns none when every
+// rule is off, leaving outgoing money unscreened.
+func newRiskRules(cfg riskConfig) ([]risk.Rule, error) {
+	action, err := risk.ParseDecision(cfg.Action)
+	if err != nil {
+		return nil, err
+	}
+	var rules []risk.Rule
+	if cfg.Blocklist != "" {
+		var accounts []string
+		for _, id := range strings.Split(cfg.Blocklist, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				accounts = append(accounts, id)
+			}
+		}
+		rules = append(rules, risk.Blocklist{Accounts: accounts})
+	}
+	if cfg.VelocityCount > 0 {
+		rules = append(rules, risk.Velocity{MaxCount: cfg.VelocityCount, Window: cfg.VelocityWindow, Action: action})
+	}
+	if cfg.AnomalyMultiplier > 0 {
+		rules = append(rules, risk.Anomaly{Multiplier: cfg.AnomalyMultiplier, MinHistory: cfg.AnomalyMinHistory, Action: action})
+	}
+	if cfg.NewDestinationCooldown > 0 {
+		rules = append(rules, risk.NewDestination{Cooldown: cfg.NewDestinationCooldown, MinAmount: cfg.NewDestinationMinAmount, Action: action})
+	}
+	return rules, nil
+}
+
+// newAuditLog opens the audit file, or keeps the log in memory when none is
+// configured.
+func newAuditLog(cfg config) (*audit.Log, error) {
+	if cfg.AuditFile == "" {
+		return audit.NewLog(), nil
+	}
+	return audit.Open(cfg.AuditFile)
+}
+
+// newOutboxPublisher picks the outbox sink from the config. It returns nil
+// when no sink is configured, leaving the outbox disabled.
+func newOutboxPublisher(cfg config) (domain.Publisher, error) {
+	if cfg.OutboxURL != "" {
+		return outbox.NewHTTPPublisher(cfg.OutboxURL, 10*time.Second), nil
+	}
+	if cfg.OutboxFile != "" {
+		return outbox.NewFilePublisher(cfg.OutboxFile)
 	}
+	return nil, nil
 }
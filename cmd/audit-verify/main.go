@@ -0,0 +1,55 @@
+// Command audit-verify checks the hash chain of an audit log file written
+// with -audit-file.
+//
+// Usage:
+//
+//	audit-verify [-head <hash>] <file>
+//
+// It exits 0 and prints the entry count and head hash when the chain is
+// intact, and 1 otherwise. With -head, it also fails unless the chain ends
+// at that hash, which catches a truncated or wholesale rewritten log.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/audit"
+)
+
+func main() {
+	head := flag.String("head", "", "expected hash of the last entry, from a copy kept elsewhere")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: audit-verify [-head <hash>] <file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *head); err != nil {
+		fmt.Fprintln(os.Stderr, "FAIL:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, wantHead string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count, head, err := audit.Verify(f)
+	if err != nil {
+		return err
+	}
+	if wantHead != "" && head != wantHead {
+		return fmt.Errorf("chain ends at %s, expected %s", head, wantHead)
+	}
+	fmt.Printf("OK: %d entries, head %s\n", count, head)
+	return nil
+}
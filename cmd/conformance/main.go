@@ -0,0 +1,75 @@
+// Command conformance runs the IPKISS test script and reports each step.
+//
+// Usage:
+//
+//	conformance [-url <url>] [-api-key <key>]
+//
+// Without -url the script runs against the HTTP API in-process. With it,
+// the server at url is reset and exercised, so never point it at one
+// holding data you need. It exits 0 when every step passes, 1 when any
+// fails and 2 on bad usage.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/conformance"
+)
+
+func main() {
+	// The in-process services log every rejected event, which would drown
+	// the report.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	url := fs.String("url", "", "server to test, instead of the API in-process")
+	apiKey := fs.String("api-key", os.Getenv("IPKISS_API_KEY"), "API key sent to the server")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout for each request")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: conformance [-url <url>] [-api-key <key>]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var runner *conformance.Runner
+	if *url != "" {
+		runner = conformance.NewRunner(*url,
+			conformance.WithAPIKey(*apiKey),
+			conformance.WithHTTPClient(&http.Client{Timeout: *timeout}))
+	} else {
+		runner = conformance.NewRunner("http://ipkiss.test", conformance.WithHandler(conformance.NewHandler()))
+	}
+
+	failed := 0
+	results := runner.Run(ctx, conformance.IPKISS)
+	for _, res := range results {
+		if res.Passed() {
+			fmt.Fprintf(stdout, "PASS  %s\n", res.Step.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(stdout, "FAIL  %s: %s\n", res.Step.Name, res.Failure())
+	}
+	fmt.Fprintf(stdout, "%d steps, %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/conformance"
+)
+
+func TestRun_InProcess(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), nil, &stdout, &stderr)
+
+	if code != 0 || !strings.HasSuffix(stdout.String(), "9 steps, 9 passed, 0 failed\n") {
+		t.Errorf("Expected every step to pass, got %d:\n%s%s", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRun_Remote(t *testing.T) {
+	// The server forgets to reset, so the second run finds account 300
+	// still holding the first run's transfer.
+	api := conformance.NewHandler()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/reset" {
+			fmt.Fprint(w, "OK")
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	if code := run(context.Background(), []string{"-url", server.URL}, &stdout, &stdout); code != 0 {
+		t.Fatalf("Expected the first run to pass, got %d:\n%s", code, stdout.String())
+	}
+	stdout.Reset()
+	code := run(context.Background(), []string{"-url", server.URL}, &stdout, &stdout)
+	if code != 1 || !strings.Contains(stdout.String(), `"destination":{"id":"300","balance":30}}`+"\n") {
+		t.Errorf("Expected the second run to fail, got %d:\n%s", code, stdout.String())
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var out bytes.Buffer
+	if code := run(context.Background(), []string{"extra"}, &out, &out); code != 2 {
+		t.Errorf("Expected exit 2, got %d", code)
+	}
+}
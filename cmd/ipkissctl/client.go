@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+)
+
+// Exit codes. Scripts can tell a missing account from a rejected request
+// or an unreachable server without parsing output.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitInvalid     = 4
+	exitDenied      = 5
+	exitUnavailable = 6
+	exitPending     = 7
+)
+
+// statusError is a response outside 2xx.
+type statusError struct {
+	Status int
+	Body   string
+}
+
+func (e *statusError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	// The IPKISS API answers a bare "0" for missing accounts and
+	// insufficient funds alike.
+	if e.Status == http.StatusNotFound && e.Body == "0" {
+		return msg + ": account not found or insufficient funds"
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// codedError carries a specific exit code for an error that is not a
+// response status.
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+func exitCode(err error) int {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.Status == http.StatusNotFound:
+			return exitNotFound
+		case statusErr.Status == http.StatusUnauthorized, statusErr.Status == http.StatusForbidden:
+			return exitDenied
+		case statusErr.Status == http.StatusTooManyRequests, statusErr.Status >= 500:
+			return exitUnavailable
+		case statusErr.Status >= 400:
+			return exitInvalid
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return exitUnavailable
+	}
+	return exitError
+}
+
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newClient(p profile, timeout time.Duration) *client {
+	return &client{
+		baseURL: strings.TrimSuffix(p.URL, "/"),
+		apiKey:  p.APIKey,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request and returns the response for any 2xx status. The
+// caller closes its body.
+func (c *client) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, c.apiKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, &statusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	return resp, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const defaultURL = "http://localhost:8080"
+
+// profile is one named server in the config file.
+type profile struct {
+	URL    string `json:"url"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// fileConfig is the config file, by default
+// $XDG_CONFIG_HOME/ipkissctl/config.json:
+//
+//	{
+//	  "default_profile": "local",
+//	  "profiles": {
+//	    "local": { "url": "http://localhost:8080" },
+//	    "staging": { "url": "https://ipkiss.staging.example", "api_key": "..." }
+//	  }
+//	}
+type fileConfig struct {
+	DefaultProfile string             `json:"default_profile"`
+	Profiles       map[string]profile `json:"profiles"`
+}
+
+// resolveProfile picks the server to talk to. Flags win over the
+// environment, which wins over the selected profile.
+func resolveProfile(configPath, name, url, apiKey string) (profile, error) {
+	explicit := configPath != ""
+	if configPath == "" {
+		configPath = os.Getenv("IPKISS_CONFIG")
+		explicit = configPath != ""
+	}
+	if configPath == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			configPath = filepath.Join(dir, "ipkissctl", "config.json")
+		}
+	}
+	if name == "" {
+		name = os.Getenv("IPKISS_PROFILE")
+	}
+
+	var p profile
+	cfg, err := readConfig(configPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit && name == "":
+	case err != nil:
+		return profile{}, err
+	default:
+		if name == "" {
+			name = cfg.DefaultProfile
+		}
+		if name != "" {
+			var ok bool
+			if p, ok = cfg.Profiles[name]; !ok {
+				return profile{}, fmt.Errorf("profile %q not found in %s", name, configPath)
+			}
+		}
+	}
+
+	for _, v := range []struct {
+		dst       *string
+		flag, env string
+	}{
+		{&p.URL, url, os.Getenv("IPKISS_URL")},
+		{&p.APIKey, apiKey, os.Getenv("IPKISS_API_KEY")},
+	} {
+		if v.flag != "" {
+			*v.dst = v.flag
+		} else if v.env != "" {
+			*v.dst = v.env
+		}
+	}
+	if p.URL == "" {
+		p.URL = defaultURL
+	}
+	return p, nil
+}
+
+func readConfig(path string) (fileConfig, error) {
+	var cfg fileConfig
+	if path == "" {
+		return cfg, fs.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,386 @@
+// Command ipkissctl is a command-line client for the IPKISS HTTP API.
+//
+// Usage:
+//
+//	ipkissctl [global flags] <command> [flags] [args]
+//
+// Commands:
+//
+//	balance [-as-of <time>] <account>
+//	deposit <account> <amount>
+//	withdraw <account> <amount>
+//	transfer <origin> <destination> <amount>
+//	reset
+//	history [-from <time>] [-to <time>] <account>
+//	export [-kind events|accounts] [-format ndjson|csv]
+//	import [-kind events|accounts] [-format ndjson|csv] [-dry-run] <file|->
+//
+// The server and API key come from -url and -api-key, then IPKISS_URL and
+// IPKISS_API_KEY, then the profile chosen with -profile, IPKISS_PROFILE or
+// the config file's default_profile. Exit codes: 0 success, 1 other error,
+// 2 usage, 3 not found or insufficient funds, 4 invalid request, 5 denied,
+// 6 server unavailable or rate limited, 7 held for review.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const usage = `usage: ipkissctl [global flags] <command> [flags] [args]
+
+commands:
+  balance [-as-of <time>] <account>
+  deposit <account> <amount>
+  withdraw <account> <amount>
+  transfer <origin> <destination> <amount>
+  reset
+  history [-from <time>] [-to <time>] <account>
+  export [-kind events|accounts] [-format ndjson|csv]
+  import [-kind events|accounts] [-format ndjson|csv] [-dry-run] <file|->
+
+global flags:
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// env is what a subcommand works with.
+type env struct {
+	client *client
+	out    printer
+	stdin  io.Reader
+}
+
+// command runs one subcommand with the arguments after its name.
+type command func(ctx context.Context, e env, args []string) error
+
+var commands = map[string]command{
+	"balance":  balanceCmd,
+	"deposit":  eventCmd("deposit"),
+	"withdraw": eventCmd("withdraw"),
+	"transfer": eventCmd("transfer"),
+	"reset":    resetCmd,
+	"history":  historyCmd,
+	"export":   exportCmd,
+	"import":   importCmd,
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ipkissctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config file (env IPKISS_CONFIG)")
+	profileName := fs.String("profile", "", "profile from the config file (env IPKISS_PROFILE)")
+	baseURL := fs.String("url", "", "server URL (env IPKISS_URL)")
+	apiKey := fs.String("api-key", "", "API key (env IPKISS_API_KEY)")
+	output := fs.String("output", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 || (*output != "table" && *output != "json") {
+		fs.Usage()
+		return exitUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "ipkissctl: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return exitUsage
+	}
+
+	p, err := resolveProfile(*configPath, *profileName, *baseURL, *apiKey)
+	if err != nil {
+		fmt.Fprintln(stderr, "ipkissctl:", err)
+		return exitUsage
+	}
+	e := env{
+		client: newClient(p, *timeout),
+		out:    printer{w: stdout, json: *output == "json"},
+		stdin:  stdin,
+	}
+	err = cmd(ctx, e, fs.Args()[1:])
+	if err == nil {
+		return exitOK
+	}
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(stderr, "ipkissctl %s: %s\n", fs.Arg(0), usageErr)
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "ipkissctl %s: %s\n", fs.Arg(0), err)
+	return exitCode(err)
+}
+
+// usageError is a bad invocation of a subcommand.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// parseFlags parses a subcommand's flags and checks it got want
+// positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string, want int, synopsis string) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error() + "; usage: " + synopsis)
+	}
+	if fs.NArg() != want {
+		return usageError("usage: " + synopsis)
+	}
+	return nil
+}
+
+func balanceCmd(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("balance", flag.ContinueOnError)
+	asOf := fs.String("as-of", "", "RFC 3339 instant to read a past balance at")
+	if err := parseFlags(fs, args, 1, "balance [-as-of <time>] <account>"); err != nil {
+		return err
+	}
+	id := fs.Arg(0)
+	query := url.Values{"account_id": {id}}
+	if *asOf != "" {
+		query.Set("as_of", *asOf)
+	}
+	resp, err := e.client.do(ctx, http.MethodGet, "/balance?"+query.Encode(), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	balance, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("unexpected balance %q", data)
+	}
+	return e.out.table(map[string]any{"account_id": id, "balance": balance},
+		[]string{"ACCOUNT", "BALANCE"},
+		[][]string{{id, strconv.Itoa(balance)}},
+	)
+}
+
+func eventCmd(eventType string) command {
+	synopsis := map[string]string{
+		"deposit":  "deposit <account> <amount>",
+		"withdraw": "withdraw <account> <amount>",
+		"transfer": "transfer <origin> <destination> <amount>",
+	}[eventType]
+	want := 2
+	if eventType == "transfer" {
+		want = 3
+	}
+	return func(ctx context.Context, e env, args []string) error {
+		fs := flag.NewFlagSet(eventType, flag.ContinueOnError)
+		if err := parseFlags(fs, args, want, synopsis); err != nil {
+			return err
+		}
+		amount, err := strconv.Atoi(fs.Arg(want - 1))
+		if err != nil {
+			return usageError("invalid amount " + fs.Arg(want-1))
+		}
+		event := domain.EventRequest{Type: eventType, Amount: amount}
+		switch eventType {
+		case "deposit":
+			event.Destination = fs.Arg(0)
+		case "withdraw":
+			event.Origin = fs.Arg(0)
+		default:
+			event.Origin, event.Destination = fs.Arg(0), fs.Arg(1)
+		}
+		body, _ := json.Marshal(event)
+
+		resp, err := e.client.do(ctx, http.MethodPost, "/event", "application/json", strings.NewReader(string(body)))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusAccepted {
+			var review domain.Review
+			if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+				return err
+			}
+			if e.out.json {
+				e.out.value(review)
+			}
+			return &codedError{code: exitPending, err: fmt.Errorf("held for review %s: %s", review.ID, review.Reason)}
+		}
+		var result domain.EventResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, a := range []struct {
+			role    string
+			account *domain.Account
+		}{{"origin", result.Origin}, {"destination", result.Destination}} {
+			if a.account != nil {
+				rows = append(rows, []string{a.role, a.account.ID, strconv.Itoa(a.account.Balance)})
+			}
+		}
+		return e.out.table(result, []string{"ROLE", "ACCOUNT", "BALANCE"}, rows)
+	}
+}
+
+func resetCmd(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0, "reset"); err != nil {
+		return err
+	}
+	resp, err := e.client.do(ctx, http.MethodPost, "/reset", "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if e.out.json {
+		return e.out.value(map[string]bool{"ok": true})
+	}
+	_, err = fmt.Fprintln(e.out.w, "OK")
+	return err
+}
+
+// statement is the JSON body of GET /accounts/{id}/statement.
+type statement struct {
+	domain.StatementHeader
+	Lines []domain.StatementLine `json:"lines"`
+	domain.StatementSummary
+}
+
+func historyCmd(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	from := fs.String("from", "", "RFC 3339 start of the period")
+	to := fs.String("to", "", "RFC 3339 end of the period, exclusive")
+	if err := parseFlags(fs, args, 1, "history [-from <time>] [-to <time>] <account>"); err != nil {
+		return err
+	}
+	query := url.Values{"format": {"json"}}
+	if *from != "" {
+		query.Set("from", *from)
+	}
+	if *to != "" {
+		query.Set("to", *to)
+	}
+	resp, err := e.client.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(fs.Arg(0))+"/statement?"+query.Encode(), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var s statement
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return err
+	}
+
+	rows := [][]string{{"", "", "opening", "", "", strconv.Itoa(s.OpeningBalance)}}
+	for _, l := range s.Lines {
+		rows = append(rows, []string{
+			strconv.FormatUint(l.Seq, 10),
+			l.Time.Format(time.RFC3339),
+			l.Type,
+			l.Counterparty,
+			strconv.Itoa(l.Amount),
+			strconv.Itoa(l.Balance),
+		})
+	}
+	rows = append(rows, []string{"", "", "closing", "", "", strconv.Itoa(s.ClosingBalance)})
+	return e.out.table(s, []string{"SEQ", "TIME", "TYPE", "COUNTERPARTY", "AMOUNT", "BALANCE"}, rows)
+}
+
+// exportCmd copies the export to stdout as the server streams it; -output
+// does not apply.
+func exportCmd(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	kind := fs.String("kind", domain.ImportEvents, "events or accounts")
+	format := fs.String("format", "ndjson", "ndjson or csv")
+	if err := parseFlags(fs, args, 0, "export [-kind events|accounts] [-format ndjson|csv]"); err != nil {
+		return err
+	}
+	query := url.Values{"kind": {*kind}, "format": {*format}}
+	resp, err := e.client.do(ctx, http.MethodGet, "/admin/export?"+query.Encode(), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(e.out.w, resp.Body)
+	return err
+}
+
+func importCmd(ctx context.Context, e env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	kind := fs.String("kind", domain.ImportEvents, "events or accounts")
+	format := fs.String("format", "", "ndjson or csv; by default csv for .csv files")
+	dryRun := fs.Bool("dry-run", false, "validate and roll back")
+	if err := parseFlags(fs, args, 1, "import [-kind events|accounts] [-format ndjson|csv] [-dry-run] <file|->"); err != nil {
+		return err
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = "ndjson"
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			*format = "csv"
+		}
+	}
+	var body io.Reader = e.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		body = f
+	}
+
+	query := url.Values{"kind": {*kind}, "format": {*format}, "dry_run": {strconv.FormatBool(*dryRun)}}
+	var report domain.ImportReport
+	resp, err := e.client.do(ctx, http.MethodPost, "/admin/import?"+query.Encode(), "", body)
+	var statusErr *statusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.Status == http.StatusUnprocessableEntity:
+		if json.Unmarshal([]byte(statusErr.Body), &report) != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			return err
+		}
+	}
+
+	var rows [][]string
+	for _, e := range report.Errors {
+		rows = append(rows, []string{strconv.Itoa(e.Line), e.Error})
+	}
+	switch {
+	case e.out.json:
+		e.out.value(report)
+	case len(rows) > 0:
+		e.out.table(nil, []string{"LINE", "ERROR"}, rows)
+	case report.DryRun:
+		fmt.Fprintf(e.out.w, "Dry run: %d %s valid, nothing applied\n", report.Records, report.Kind)
+	default:
+		fmt.Fprintf(e.out.w, "Imported %d %s\n", report.Records, report.Kind)
+	}
+	if len(report.Errors) > 0 {
+		return &codedError{code: exitInvalid, err: fmt.Errorf("%d errors, nothing applied", len(report.Errors))}
+	}
+	return nil
+}
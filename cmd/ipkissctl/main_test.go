@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// newTestServer fakes the parts of the API the commands use.
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /balance", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(auth.APIKeyHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("account_id") {
+		case "100":
+			io.WriteString(w, "20")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "0")
+		}
+	})
+	mux.HandleFunc("POST /event", func(w http.ResponseWriter, r *http.Request) {
+		var event domain.EventRequest
+		json.NewDecoder(r.Body).Decode(&event)
+		switch {
+		case event.Amount > 1000:
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(domain.Review{ID: "rev_1", Reason: "too large"})
+		case event.Origin == "999":
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "0")
+		default:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(domain.EventResponse{
+				Origin:      &domain.Account{ID: event.Origin, Balance: 5},
+				Destination: &domain.Account{ID: event.Destination, Balance: event.Amount},
+			})
+		}
+	})
+	mux.HandleFunc("POST /admin/import", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		report := domain.ImportReport{Kind: r.URL.Query().Get("kind"), DryRun: r.URL.Query().Get("dry_run") == "true", Records: 2}
+		if r.URL.Query().Get("format") != "csv" || !bytes.HasPrefix(data, []byte("type,")) {
+			report.Errors = []domain.ImportLineError{{Line: 1, Error: "not csv"}}
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+	mux.HandleFunc("GET /admin/export", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"100","balance":20}`+"\n")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Setenv("IPKISS_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("IPKISS_PROFILE", "")
+	t.Setenv("IPKISS_URL", "")
+	t.Setenv("IPKISS_API_KEY", "")
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Balance(t *testing.T) {
+	server := newTestServer(t)
+
+	code, out, _ := runCmd(t, "", "-url", server.URL, "-api-key", "secret", "balance", "100")
+	if code != exitOK || !strings.Contains(out, "ACCOUNT  BALANCE\n100      20\n") {
+		t.Errorf("Expected a balance table, got %d %q", code, out)
+	}
+
+	code, out, _ = runCmd(t, "", "-url", server.URL, "-api-key", "secret", "-output", "json", "balance", "100")
+	var got struct {
+		AccountID string `json:"account_id"`
+		Balance   int    `json:"balance"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); code != exitOK || err != nil || got.Balance != 20 {
+		t.Errorf("Expected a JSON balance of 20, got %d %q", code, out)
+	}
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	server := newTestServer(t)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"deposit", []string{"-url", server.URL, "deposit", "100", "10"}, exitOK},
+		{"not found", []string{"-url", server.URL, "-api-key", "secret", "balance", "300"}, exitNotFound},
+		{"unauthenticated", []string{"-url", server.URL, "balance", "100"}, exitDenied},
+		{"frozen", []string{"-url", server.URL, "withdraw", "999", "1"}, exitDenied},
+		{"held", []string{"-url", server.URL, "transfer", "100", "300", "5000"}, exitPending},
+		{"unreachable", []string{"-url", closed.URL, "reset"}, exitUnavailable},
+		{"bad amount", []string{"-url", server.URL, "deposit", "100", "ten"}, exitUsage},
+		{"missing args", []string{"-url", server.URL, "transfer", "100", "5"}, exitUsage},
+		{"unknown command", []string{"-url", server.URL, "loan", "100"}, exitUsage},
+	}
+	for _, tt := range tests {
+		if code, _, stderr := runCmd(t, "", tt.args...); code != tt.want {
+			t.Errorf("Expected exit %d for %s, got %d (%s)", tt.want, tt.name, code, stderr)
+		}
+	}
+}
+
+func TestRun_Import(t *testing.T) {
+	server := newTestServer(t)
+	csvFile := filepath.Join(t.TempDir(), "events.csv")
+	os.WriteFile(csvFile, []byte("type,destination,amount\ndeposit,100,10\n"), 0o600)
+
+	code, out, _ := runCmd(t, "", "-url", server.URL, "import", "-dry-run", csvFile)
+	if code != exitOK || out != "Dry run: 2 events valid, nothing applied\n" {
+		t.Errorf("Expected a clean dry run of the CSV file, got %d %q", code, out)
+	}
+
+	code, out, _ = runCmd(t, `{"type":"deposit"}`, "-url", server.URL, "import", "-")
+	if code != exitInvalid || !strings.Contains(out, "1     not csv") {
+		t.Errorf("Expected line errors for stdin, got %d %q", code, out)
+	}
+}
+
+func TestRun_Export(t *testing.T) {
+	server := newTestServer(t)
+
+	code, out, _ := runCmd(t, "", "-url", server.URL, "export", "-kind", "accounts")
+	if code != exitOK || out != `{"id":"100","balance":20}`+"\n" {
+		t.Errorf("Expected the export copied to stdout, got %d %q", code, out)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{
+		"default_profile": "local",
+		"profiles": {
+			"local": {"url": "http://localhost:8080"},
+			"staging": {"url": "https://staging.example", "api_key": "k1"}
+		}
+	}`), 0o600)
+	t.Setenv("IPKISS_URL", "")
+	t.Setenv("IPKISS_API_KEY", "")
+	t.Setenv("IPKISS_PROFILE", "")
+
+	if p, err := resolveProfile(path, "", "", ""); err != nil || p.URL != "http://localhost:8080" {
+		t.Errorf("Expected the default profile, got %+v (err %v)", p, err)
+	}
+	if p, _ := resolveProfile(path, "staging", "", ""); p.URL != "https://staging.example" || p.APIKey != "k1" {
+		t.Errorf("Expected the staging profile, got %+v", p)
+	}
+	t.Setenv("IPKISS_API_KEY", "k2")
+	if p, _ := resolveProfile(path, "staging", "", ""); p.APIKey != "k2" {
+		t.Errorf("Expected the environment to override the profile, got %+v", p)
+	}
+	if p, _ := resolveProfile(path, "staging", "http://override", "k3"); p.URL != "http://override" || p.APIKey != "k3" {
+		t.Errorf("Expected flags to override everything, got %+v", p)
+	}
+	if _, err := resolveProfile(path, "prod", "", ""); err == nil {
+		t.Errorf("Expected an unknown profile to fail")
+	}
+	if _, err := resolveProfile(filepath.Join(t.TempDir(), "none.json"), "", "", ""); err == nil {
+		t.Errorf("Expected an explicit missing config file to fail")
+	}
+}
@@ -0,0 +1,247 @@
+// Command replay applies a file of events to an IPKISS service and reports
+// what happened, for regression testing and capacity planning.
+//
+// Usage:
+//
+//	replay [flags] <file|->
+//
+// Each line of the file is an event as accepted by POST /event, optionally
+// with the outcome it should have. Accounts left out of an expectation are
+// not checked:
+//
+//	{"type":"deposit","destination":"100","amount":10,"expect":{"status":201,"destination":{"id":"100","balance":10}}}
+//
+// Without -url the events run against a fresh in-process EventService; with
+// it, against that server, which is only reset first with -reset. The
+// report lists the final balance of every account the file touches, the
+// status codes, failures, throughput and latency percentiles, and every
+// outcome that differs from its expectation. -record writes the file back
+// with each expectation set to what actually happened, as a baseline for
+// later runs.
+//
+// Expectations only make sense with -concurrency 1, since concurrent events
+// may apply in any order. It exits 0 when every expectation held, 1 on a
+// mismatch or an event that could not be delivered, and 2 on bad usage or
+// input.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// record is one line of the input file.
+type record struct {
+	domain.EventRequest
+	Expect *outcome `json:"expect,omitempty"`
+
+	line int
+}
+
+func main() {
+	// The services log every rejected event, which would drown the report.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	url := fs.String("url", "", "server to replay against, instead of an in-process service")
+	apiKey := fs.String("api-key", os.Getenv("IPKISS_API_KEY"), "API key sent to the server")
+	reset := fs.Bool("reset", false, "reset the server before replaying, wiping all of its accounts")
+	concurrency := fs.Int("concurrency", 1, "events in flight at once")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout for each request to the server")
+	recordPath := fs.String("record", "", "write the events with their actual outcomes to this file")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: replay [flags] <file|->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *concurrency < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	recs, err := readRecords(fs.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "replay:", err)
+		return 2
+	}
+
+	var (
+		t    target
+		name = "in-process"
+	)
+	if *url != "" {
+		r := newRemote(*url, *apiKey, *timeout)
+		if *reset {
+			if err := r.reset(ctx); err != nil {
+				fmt.Fprintln(stderr, "replay:", err)
+				return 1
+			}
+		}
+		t, name = r, *url
+	} else {
+		t = newInProcess()
+	}
+
+	start := time.Now()
+	results := replay(ctx, t, recs, *concurrency)
+	elapsed := time.Since(start)
+
+	// The report is still worth printing when the balances cannot be read,
+	// as it says which events failed to get through.
+	balances, balancesErr := t.balances(ctx, accountIDs(recs))
+	if balancesErr != nil {
+		fmt.Fprintln(stderr, "replay: reading balances:", balancesErr)
+	}
+	rep := newReport(name, recs, results, elapsed, balances)
+
+	if *recordPath != "" {
+		if err := writeRecords(*recordPath, recs, results); err != nil {
+			fmt.Fprintln(stderr, "replay:", err)
+			return 1
+		}
+	}
+	if *jsonOut {
+		err = rep.writeJSON(stdout)
+	} else {
+		err = rep.writeText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "replay:", err)
+		return 1
+	}
+	if len(rep.Mismatches) > 0 || rep.Undelivered > 0 || balancesErr != nil {
+		return 1
+	}
+	return 0
+}
+
+// readRecords reads path, or stdin for "-". Unknown fields are rejected so
+// a misspelt expectation is not silently ignored.
+func readRecords(path string, stdin io.Reader) ([]record, error) {
+	in := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var recs []record
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		rec := record{line: line}
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, errors.New("no events to replay")
+	}
+	return recs, nil
+}
+
+// replay sends every record with up to concurrency in flight and returns
+// the results in input order. It stops sending once ctx is done; the
+// remaining records come back undelivered.
+func replay(ctx context.Context, t target, recs []record, concurrency int) []result {
+	results := make([]result, len(recs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			for i := range next {
+				start := time.Now()
+				o, err := t.send(ctx, recs[i].EventRequest)
+				results[i] = result{outcome: o, latency: time.Since(start), err: err}
+			}
+		})
+	}
+	for i := range recs {
+		if ctx.Err() != nil {
+			results[i] = result{err: ctx.Err()}
+			continue
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// accountIDs lists every account the records name, in order of first use.
+func accountIDs(recs []record) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, rec := range recs {
+		for _, id := range []string{rec.Origin, rec.Destination} {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// writeRecords writes recs with each expectation replaced by the actual
+// outcome. Undelivered events keep no expectation.
+func writeRecords(path string, recs []record, results []result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i, rec := range recs {
+		rec.Expect = nil
+		if results[i].err == nil {
+			rec.Expect = &results[i].outcome
+		}
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// ipkissScript is the official IPKISS sequence with its expected outcomes.
+const ipkissScript = `
+{"type":"withdraw","origin":"200","amount":10,"expect":{"status":404}}
+{"type":"deposit","destination":"100","amount":10,"expect":{"status":201,"destination":{"id":"100","balance":10}}}
+{"type":"deposit","destination":"100","amount":10,"expect":{"status":201,"destination":{"id":"100","balance":20}}}
+{"type":"withdraw","origin":"100","amount":5,"expect":{"status":201,"origin":{"id":"100","balance":15}}}
+{"type":"transfer","origin":"100","destination":"300","amount":15,"expect":{"status":201,"origin":{"id":"100","balance":0},"destination":{"id":"300","balance":15}}}
+{"type":"transfer","origin":"200","destination":"300","amount":15,"expect":{"status":404}}
+`
+
+func replayCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_InProcess(t *testing.T) {
+	code, out, stderr := replayCmd(t, ipkissScript, "-")
+	if code != 0 {
+		t.Fatalf("Expected exit 0, got %d: %s%s", code, out, stderr)
+	}
+	for _, want := range []string{
+		"Events:        6 (4 succeeded, 2 failed, 0 undelivered)",
+		"Statuses:      201=4 404=2",
+		"Expectations:  6 checked, 0 mismatched",
+		"100      0\n300      15\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_Mismatch(t *testing.T) {
+	script := strings.Replace(ipkissScript, `"balance":20`, `"balance":25`, 1)
+
+	code, out, _ := replayCmd(t, script, "-json", "-")
+	if code != 1 {
+		t.Errorf("Expected exit 1, got %d", code)
+	}
+	var rep report
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("Expected a JSON report, got %v: %s", err, out)
+	}
+	if len(rep.Mismatches) != 1 || rep.Mismatches[0].Line != 4 || rep.Mismatches[0].Got.Destination.Balance != 20 {
+		t.Errorf("Expected one mismatch on line 4, got %+v", rep.Mismatches)
+	}
+}
+
+func TestRun_Record(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "events.jsonl")
+	baseline := filepath.Join(dir, "baseline.jsonl")
+	os.WriteFile(input, []byte(`{"type":"deposit","destination":"100","amount":10}
+{"type":"withdraw","origin":"100","amount":50}
+`), 0o600)
+
+	if code, _, stderr := replayCmd(t, "", "-record", baseline, input); code != 0 {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	data, _ := os.ReadFile(baseline)
+	if !strings.Contains(string(data), `"expect":{"status":404}`) {
+		t.Errorf("Expected the recorded outcomes, got:\n%s", data)
+	}
+
+	code, out, _ := replayCmd(t, "", baseline)
+	if code != 0 || !strings.Contains(out, "2 checked, 0 mismatched") {
+		t.Errorf("Expected the baseline to replay cleanly, got %d:\n%s", code, out)
+	}
+}
+
+func TestRun_Remote(t *testing.T) {
+	// The fake server applies events to an in-process target, so a remote
+	// run must produce the same outcomes as a local one.
+	backend := newInProcess()
+	resets := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /reset", func(w http.ResponseWriter, r *http.Request) {
+		resets++
+		backend = newInProcess()
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /event", func(w http.ResponseWriter, r *http.Request) {
+		var event domain.EventRequest
+		json.NewDecoder(r.Body).Decode(&event)
+		o, _ := backend.send(r.Context(), event)
+		w.WriteHeader(o.Status)
+		if o.Status == http.StatusCreated {
+			json.NewEncoder(w).Encode(domain.EventResponse{Origin: o.Origin, Destination: o.Destination})
+		} else {
+			fmt.Fprint(w, "0")
+		}
+	})
+	mux.HandleFunc("GET /balance", func(w http.ResponseWriter, r *http.Request) {
+		balances, _ := backend.balances(r.Context(), []string{r.URL.Query().Get("account_id")})
+		for _, balance := range balances {
+			fmt.Fprint(w, balance)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "0")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	code, out, stderr := replayCmd(t, ipkissScript, "-url", server.URL, "-")
+	if code != 0 || resets != 0 || !strings.Contains(out, "6 checked, 0 mismatched") {
+		t.Errorf("Expected a clean remote replay without a reset, got %d (%d resets):\n%s%s", code, resets, out, stderr)
+	}
+
+	// The second run only matches again if -reset clears the first.
+	code, out, stderr = replayCmd(t, ipkissScript, "-url", server.URL, "-reset", "-")
+	if code != 0 || resets != 1 || !strings.Contains(out, "6 checked, 0 mismatched") {
+		t.Errorf("Expected a clean remote replay after one reset, got %d (%d resets):\n%s%s", code, resets, out, stderr)
+	}
+
+	server.Close()
+	code, out, _ = replayCmd(t, ipkissScript, "-url", server.URL, "-")
+	if code != 1 || !strings.Contains(out, "0 succeeded, 0 failed, 6 undelivered") {
+		t.Errorf("Expected every event undelivered, got %d:\n%s", code, out)
+	}
+}
+
+func TestRun_Concurrent(t *testing.T) {
+	var script strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&script, `{"type":"deposit","destination":"%d","amount":1}`+"\n", 100+i%4)
+	}
+
+	code, out, _ := replayCmd(t, script.String(), "-concurrency", "8", "-json", "-")
+	var rep report
+	json.Unmarshal([]byte(out), &rep)
+	if code != 0 || rep.Succeeded != 200 || len(rep.Balances) != 4 {
+		t.Fatalf("Expected 200 deposits into 4 accounts, got %d: %s", code, out)
+	}
+	for _, a := range rep.Balances {
+		if a.Balance != 50 {
+			t.Errorf("Expected account %s to hold 50, got %d", a.ID, a.Balance)
+		}
+	}
+}
+
+func TestRun_BadInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{"misspelt field", `{"type":"deposit","destination":"100","amount":10,"expected":{"status":201}}`, []string{"-"}},
+		{"empty", "\n\n", []string{"-"}},
+		{"no file", "", nil},
+		{"bad concurrency", ipkissScript, []string{"-concurrency", "0", "-"}},
+	}
+	for _, tt := range tests {
+		if code, _, _ := replayCmd(t, tt.stdin, tt.args...); code != 2 {
+			t.Errorf("Expected exit 2 for %s, got %d", tt.name, code)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	for p, want := range map[int]float64{50: 50, 90: 90, 99: 99, 100: 100} {
+		if got := percentile(latencies, p); got != want {
+			t.Errorf("Expected p%d to be %v, got %v", p, want, got)
+		}
+	}
+	if got := percentile(latencies[:1], 99); got != 1 {
+		t.Errorf("Expected a single sample to be every percentile, got %v", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("Expected no samples to give 0, got %v", got)
+	}
+}
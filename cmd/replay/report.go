@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// result is what happened to one record.
+type result struct {
+	outcome outcome
+	latency time.Duration
+	err     error
+}
+
+type mismatch struct {
+	Line     int     `json:"line"`
+	Expected outcome `json:"expected"`
+	Got      outcome `json:"got"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// latency is in milliseconds.
+type latency struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+type report struct {
+	Target      string           `json:"target"`
+	Events      int              `json:"events"`
+	Succeeded   int              `json:"succeeded"`
+	Failed      int              `json:"failed"`
+	Undelivered int              `json:"undelivered"`
+	Statuses    map[int]int      `json:"statuses"`
+	Seconds     float64          `json:"duration_seconds"`
+	Throughput  float64          `json:"events_per_second"`
+	LatencyMS   latency          `json:"latency_ms"`
+	Checked     int              `json:"checked"`
+	Mismatches  []mismatch       `json:"mismatches,omitempty"`
+	Balances    []domain.Account `json:"balances"`
+}
+
+func newReport(targetName string, recs []record, results []result, elapsed time.Duration, balances map[string]int) *report {
+	rep := &report{
+		Target:   targetName,
+		Events:   len(recs),
+		Statuses: make(map[int]int),
+		Seconds:  elapsed.Seconds(),
+		Balances: []domain.Account{},
+	}
+	if elapsed > 0 {
+		rep.Throughput = float64(len(recs)) / elapsed.Seconds()
+	}
+
+	latencies := make([]time.Duration, 0, len(results))
+	for i, res := range results {
+		latencies = append(latencies, res.latency)
+		switch {
+		case res.err != nil:
+			rep.Undelivered++
+		case res.outcome.Status/100 == 2:
+			rep.Succeeded++
+			rep.Statuses[res.outcome.Status]++
+		default:
+			rep.Failed++
+			rep.Statuses[res.outcome.Status]++
+		}
+
+		want := recs[i].Expect
+		if want == nil {
+			continue
+		}
+		rep.Checked++
+		if res.err != nil {
+			rep.Mismatches = append(rep.Mismatches, mismatch{Line: recs[i].line, Expected: *want, Error: res.err.Error()})
+		} else if !want.matches(res.outcome) {
+			rep.Mismatches = append(rep.Mismatches, mismatch{Line: recs[i].line, Expected: *want, Got: res.outcome})
+		}
+	}
+
+	slices.Sort(latencies)
+	rep.LatencyMS = latency{
+		P50: percentile(latencies, 50),
+		P90: percentile(latencies, 90),
+		P99: percentile(latencies, 99),
+		Max: percentile(latencies, 100),
+	}
+
+	for id, balance := range balances {
+		rep.Balances = append(rep.Balances, domain.Account{ID: id, Balance: balance})
+	}
+	slices.SortFunc(rep.Balances, func(a, b domain.Account) int { return strings.Compare(a.ID, b.ID) })
+	return rep
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies in
+// milliseconds.
+func percentile(sorted []time.Duration, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return float64(sorted[rank-1]) / float64(time.Millisecond)
+}
+
+func (rep *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+func (rep *report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Target:\t%s\n", rep.Target)
+	fmt.Fprintf(tw, "Events:\t%d (%d succeeded, %d failed, %d undelivered)\n", rep.Events, rep.Succeeded, rep.Failed, rep.Undelivered)
+
+	statuses := make([]int, 0, len(rep.Statuses))
+	for status := range rep.Statuses {
+		statuses = append(statuses, status)
+	}
+	slices.Sort(statuses)
+	var counts []string
+	for _, status := range statuses {
+		counts = append(counts, fmt.Sprintf("%d=%d", status, rep.Statuses[status]))
+	}
+	fmt.Fprintf(tw, "Statuses:\t%s\n", strings.Join(counts, " "))
+	fmt.Fprintf(tw, "Duration:\t%.3fs (%.0f events/s)\n", rep.Seconds, rep.Throughput)
+	fmt.Fprintf(tw, "Latency:\tp50 %.3fms  p90 %.3fms  p99 %.3fms  max %.3fms\n",
+		rep.LatencyMS.P50, rep.LatencyMS.P90, rep.LatencyMS.P99, rep.LatencyMS.Max)
+	fmt.Fprintf(tw, "Expectations:\t%d checked, %d mismatched\n", rep.Checked, len(rep.Mismatches))
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ACCOUNT\tBALANCE")
+	for _, a := range rep.Balances {
+		fmt.Fprintf(tw, "%s\t%d\n", a.ID, a.Balance)
+	}
+
+	if len(rep.Mismatches) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "LINE\tEXPECTED\tGOT")
+		for _, m := range rep.Mismatches {
+			got := m.Got.String()
+			if m.Error != "" {
+				got = "error: " + m.Error
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", m.Line, m.Expected, got)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+// outcome is what one event produced, in the terms of the HTTP API so that
+// in-process and remote runs of the same file compare equal.
+type outcome struct {
+	Status      int             `json:"status"`
+	Origin      *domain.Account `json:"origin,omitempty"`
+	Destination *domain.Account `json:"destination,omitempty"`
+}
+
+// matches reports whether got satisfies the expectation o. Accounts left
+// out of o are not checked.
+func (o outcome) matches(got outcome) bool {
+	return o.Status == got.Status &&
+		(o.Origin == nil || sameAccount(o.Origin, got.Origin)) &&
+		(o.Destination == nil || sameAccount(o.Destination, got.Destination))
+}
+
+func (o outcome) String() string {
+	s := strconv.Itoa(o.Status)
+	if o.Origin != nil {
+		s += fmt.Sprintf(" origin %s=%d", o.Origin.ID, o.Origin.Balance)
+	}
+	if o.Destination != nil {
+		s += fmt.Sprintf(" destination %s=%d", o.Destination.ID, o.Destination.Balance)
+	}
+	return s
+}
+
+func sameAccount(want, got *domain.Account) bool {
+	return got != nil && want.ID == got.ID && want.Balance == got.Balance
+}
+
+// target applies events and reads balances back. An error from send means
+// the event could not be delivered at all, not that it was rejected.
+type target interface {
+	send(ctx context.Context, event domain.EventRequest) (outcome, error)
+	// balances returns the balance of every account in ids that exists.
+	balances(ctx context.Context, ids []string) (map[string]int, error)
+}
+
+// inProcess replays against a fresh in-memory repository, with the same
+// validation and status codes as the HTTP handler but no network, auth or
+// rate limits in the way.
+type inProcess struct {
+	accounts domain.AccountService
+	events   domain.EventService
+	validate *validator.Validate
+}
+
+func newInProcess() *inProcess {
+	accounts := service.NewAccountService(repository.NewInMemoryRepository())
+	return &inProcess{
+		accounts: accounts,
+		events:   service.NewEventService(accounts),
+		validate: validator.New(),
+	}
+}
+
+func (t *inProcess) send(ctx context.Context, event domain.EventRequest) (outcome, error) {
+	if err := t.validate.Struct(event); err != nil {
+		return outcome{Status: http.StatusBadRequest}, nil
+	}
+	resp, err := t.events.ProcessEvent(ctx, event)
+	switch {
+	case err == nil:
+		return outcome{Status: http.StatusCreated, Origin: resp.Origin, Destination: resp.Destination}, nil
+	case ctx.Err() != nil:
+		return outcome{}, ctx.Err()
+	case errors.Is(err, domain.ErrAccountFrozen), errors.Is(err, domain.ErrAccountBlocked):
+		return outcome{Status: http.StatusForbidden}, nil
+	default:
+		return outcome{Status: http.StatusNotFound}, nil
+	}
+}
+
+func (t *inProcess) balances(ctx context.Context, ids []string) (map[string]int, error) {
+	out := make(map[string]int, len(ids))
+	for _, id := range ids {
+		balance, err := t.accounts.GetBalance(ctx, id)
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[id] = balance
+	}
+	return out, nil
+}
+
+// remote replays against a running server.
+type remote struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newRemote(baseURL, apiKey string, timeout time.Duration) *remote {
+	return &remote{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (t *remote) send(ctx context.Context, event domain.EventRequest) (outcome, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return outcome{}, err
+	}
+	status, data, err := t.do(ctx, http.MethodPost, "/event", body)
+	if err != nil {
+		return outcome{}, err
+	}
+	o := outcome{Status: status}
+	if status == http.StatusCreated {
+		var resp domain.EventResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return outcome{}, fmt.Errorf("decoding response: %w", err)
+		}
+		o.Origin, o.Destination = resp.Origin, resp.Destination
+	}
+	return o, nil
+}
+
+func (t *remote) balances(ctx context.Context, ids []string) (map[string]int, error) {
+	out := make(map[string]int, len(ids))
+	for _, id := range ids {
+		status, data, err := t.do(ctx, http.MethodGet, "/balance?account_id="+url.QueryEscape(id), nil)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case http.StatusOK:
+			balance, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil {
+				return nil, fmt.Errorf("balance of %s: %w", id, err)
+			}
+			out[id] = balance
+		case http.StatusNotFound:
+		default:
+			return nil, fmt.Errorf("balance of %s: %d %s", id, status, http.StatusText(status))
+		}
+	}
+	return out, nil
+}
+
+// reset clears the server's state so a replay starts from nothing.
+func (t *remote) reset(ctx context.Context) error {
+	status, _, err := t.do(ctx, http.MethodPost, "/reset", nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("reset: %d %s", status, http.StatusText(status))
+	}
+	return nil
+}
+
+func (t *remote) do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, t.apiKey)
+	}
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
@@ -0,0 +1,205 @@
+// Package audit keeps a tamper-evident, hash-chained record of
+// administrative actions.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// GenesisHash is the PrevHash of the first entry.
+var GenesisHash = strings.Repeat("0", 64)
+
+// Anonymous is the actor recorded when authentication is disabled.
+const Anonymous = "anonymous"
+
+const defaultLimit = 100
+
+// Log implements domain.AuditLog. Entries are kept in memory and, when the
+// log was opened with a file, appended and synced to it as NDJSON before
+// Record returns.
+type Log struct {
+	now func() time.Time
+
+	entries []domain.AuditEntry
+	file    *os.File
+	mu      sync.RWMutex
+}
+
+// NewLog returns a log that is lost on restart.
+func NewLog() *Log {
+	return &Log{now: time.Now}
+}
+
+// Open continues the chain in path, creating the file if needed. It fails if
+// the existing chain does not verify.
+func Open(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := read(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+	return &Log{now: time.Now, entries: entries, file: f}, nil
+}
+
+// Close closes the backing file, if any.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Log) Record(ctx context.Context, action, target string, before, after any) error {
+	entry := domain.AuditEntry{
+		Actor:  Anonymous,
+		Action: action,
+		Target: target,
+	}
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		entry.Actor = p.ID
+	}
+	var err error
+	if entry.Before, err = marshal(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshal(after); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = uint64(len(l.entries)) + 1
+	entry.Time = l.now().UTC()
+	entry.PrevHash = GenesisHash
+	if n := len(l.entries); n > 0 {
+		entry.PrevHash = l.entries[n-1].Hash
+	}
+	entry.Hash = Hash(entry)
+
+	if l.file != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := l.file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		if err := l.file.Sync(); err != nil {
+			return err
+		}
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *Log) Entries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for _, e := range l.entries[min(filter.AfterSeq, uint64(len(l.entries))):] {
+		if filter.Actor != "" && e.Actor != filter.Actor ||
+			filter.Action != "" && e.Action != filter.Action ||
+			filter.Target != "" && e.Target != filter.Target {
+			continue
+		}
+		entries = append(entries, e)
+		if len(entries) == filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Hash returns the hash of e, computed over its JSON encoding with Hash left
+// empty.
+func Hash(e domain.AuditEntry) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks an NDJSON audit chain. It returns the number of entries and
+// the hash of the last one, which can be compared with a copy kept
+// elsewhere to detect truncation or a rewritten chain.
+func Verify(r io.Reader) (count int, head string, err error) {
+	entries, err := read(r)
+	if err != nil {
+		return 0, "", err
+	}
+	head = GenesisHash
+	if n := len(entries); n > 0 {
+		head = entries[n-1].Hash
+	}
+	return len(entries), head, nil
+}
+
+func read(r io.Reader) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	prev := GenesisHash
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case e.Seq != uint64(len(entries))+1:
+			return nil, fmt.Errorf("line %d: expected seq %d, got %d", line, len(entries)+1, e.Seq)
+		case e.PrevHash != prev:
+			return nil, fmt.Errorf("line %d: seq %d does not follow the previous entry", line, e.Seq)
+		case Hash(e) != e.Hash:
+			return nil, fmt.Errorf("line %d: seq %d has been modified", line, e.Seq)
+		}
+		entries = append(entries, e)
+		prev = e.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func marshal(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.New("audit state is not serializable: " + err.Error())
+	}
+	return data, nil
+}
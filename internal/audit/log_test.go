@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestRecord_Chain(t *testing.T) {
+	l := NewLog()
+	ctx := auth.WithPrincipal(context.Background(), &domain.Principal{ID: "alice", Role: domain.RoleAdmin})
+
+	l.Record(ctx, domain.AuditFreeze, "100", domain.Account{ID: "100"}, domain.Account{ID: "100", Frozen: true})
+	l.Record(context.Background(), domain.AuditReset, "", nil, nil)
+
+	entries, _ := l.Entries(context.Background(), domain.AuditFilter{})
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Actor != "alice" || entries[1].Actor != Anonymous {
+		t.Errorf("Expected actors alice and anonymous, got %q and %q", entries[0].Actor, entries[1].Actor)
+	}
+	if entries[0].PrevHash != GenesisHash || entries[1].PrevHash != entries[0].Hash {
+		t.Errorf("Expected entries to be chained")
+	}
+	if string(entries[0].After) != `{"id":"100","balance":0,"frozen":true}` {
+		t.Errorf("Expected after state to be stored, got %s", entries[0].After)
+	}
+}
+
+func TestEntries_Filter(t *testing.T) {
+	l := NewLog()
+	for _, target := range []string{"100", "200", "100", "100"} {
+		l.Record(context.Background(), domain.AuditFreeze, target, nil, nil)
+	}
+
+	entries, _ := l.Entries(context.Background(), domain.AuditFilter{Target: "100", AfterSeq: 1, Limit: 1})
+	if len(entries) != 1 || entries[0].Seq != 3 {
+		t.Errorf("Expected only seq 3, got %+v", entries)
+	}
+}
+
+func TestOpen_ContinuesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Record(context.Background(), domain.AuditReset, "", nil, nil)
+	l.Close()
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatalf("Expected the chain to reopen, got %v", err)
+	}
+	l.Record(context.Background(), domain.AuditFreeze, "100", nil, map[string]bool{"frozen": true})
+	l.Close()
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	count, head, err := Verify(f)
+	if err != nil {
+		t.Fatalf("Expected the chain to verify, got %v", err)
+	}
+	if count != 2 || head == GenesisHash {
+		t.Errorf("Expected 2 entries with a head hash, got %d and %s", count, head)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, _ := Open(path)
+	l.Record(context.Background(), domain.AuditFreeze, "100", nil, nil)
+	l.Record(context.Background(), domain.AuditFreeze, "200", nil, nil)
+	l.Record(context.Background(), domain.AuditFreeze, "300", nil, nil)
+	l.Close()
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+
+	tests := map[string]string{
+		"edited":  strings.Replace(string(data), `"target":"200"`, `"target":"999"`, 1),
+		"removed": lines[0] + lines[2],
+		"swapped": lines[1] + lines[0] + lines[2],
+	}
+	for name, content := range tests {
+		if _, _, err := Verify(strings.NewReader(content)); err == nil {
+			t.Errorf("Expected %s chain to fail verification", name)
+		}
+		tampered := filepath.Join(t.TempDir(), name)
+		os.WriteFile(tampered, []byte(content), 0o600)
+		if _, err := Open(tampered); err == nil {
+			t.Errorf("Expected Open to refuse the %s chain", name)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyEntry is one line of the key file. Only the SHA-256 of the key is
+// stored, so a leaked file does not leak usable keys.
+type APIKeyEntry struct {
+	ID        string      `json:"id"`
+	KeySHA256 string      `json:"key_sha256"`
+	Role      domain.Role `json:"role"`
+	Accounts  []string    `json:"accounts,omitempty"`
+}
+
+// APIKeys authenticates requests by the X-API-Key header.
+type APIKeys struct {
+	byHash map[string]*domain.Principal
+}
+
+func NewAPIKeys(entries []APIKeyEntry) (*APIKeys, error) {
+	keys := &APIKeys{byHash: make(map[string]*domain.Principal, len(entries))}
+	for i, e := range entries {
+		if e.ID == "" {
+			return nil, fmt.Errorf("key %d: missing id", i)
+		}
+		if b, err := hex.DecodeString(e.KeySHA256); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("key %q: key_sha256 must be a hex SHA-256 digest", e.ID)
+		}
+		if !e.Role.Valid() {
+			return nil, fmt.Errorf("key %q: unknown role %q", e.ID, e.Role)
+		}
+		if e.Role == domain.RoleAccountOwner && len(e.Accounts) == 0 {
+			return nil, fmt.Errorf("key %q: account-owner keys need accounts", e.ID)
+		}
+		if _, ok := keys.byHash[e.KeySHA256]; ok {
+			return nil, fmt.Errorf("key %q: duplicate key", e.ID)
+		}
+		keys.byHash[e.KeySHA256] = &domain.Principal{ID: e.ID, Role: e.Role, Accounts: e.Accounts}
+	}
+	return keys, nil
+}
+
+// LoadAPIKeys reads a JSON array of APIKeyEntry from path.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseAPIKeys(f)
+}
+
+func ParseAPIKeys(r io.Reader) (*APIKeys, error) {
+	var entries []APIKeyEntry
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parsing API keys: %w", err)
+	}
+	return NewAPIKeys(entries)
+}
+
+func (k *APIKeys) Authenticate(r *http.Request) (*domain.Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	p, ok := k.byHash[HashAPIKey(key)]
+	if !ok {
+		return nil, &Error{Code: "invalid_api_key", Reason: "unknown API key"}
+	}
+	return p, nil
+}
+
+func (k *APIKeys) Challenge() string {
+	return "ApiKey"
+}
+
+// HashAPIKey returns the value stored in key_sha256 for key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keys, err := ParseAPIKeys(strings.NewReader(`[
+		{"id": "ops", "key_sha256": "` + HashAPIKey("ops-secret") + `", "role": "operator"},
+		{"id": "alice", "key_sha256": "` + HashAPIKey("alice-secret") + `", "role": "account-owner", "accounts": ["100"]}
+	]`))
+	if err != nil {
+		t.Fatalf("Expected no error parsing keys: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	req.Header.Set(APIKeyHeader, "alice-secret")
+	p, err := keys.Authenticate(req)
+	if err != nil || p == nil {
+		t.Fatalf("Expected principal, got %v, %v", p, err)
+	}
+	if p.ID != "alice" || p.Role != domain.RoleAccountOwner || len(p.Accounts) != 1 {
+		t.Errorf("Expected alice as account owner of 100, got %+v", p)
+	}
+
+	req.Header.Set(APIKeyHeader, "wrong")
+	if _, err := keys.Authenticate(req); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated for unknown key, got %v", err)
+	}
+
+	req.Header.Del(APIKeyHeader)
+	if p, err := keys.Authenticate(req); p != nil || err != nil {
+		t.Errorf("Expected anonymous request, got %v, %v", p, err)
+	}
+}
+
+func TestParseAPIKeys_Invalid(t *testing.T) {
+	hash := HashAPIKey("secret")
+	tests := map[string]string{
+		"plaintext key":          `[{"id": "a", "key_sha256": "secret", "role": "admin"}]`,
+		"unknown role":           `[{"id": "a", "key_sha256": "` + hash + `", "role": "root"}]`,
+		"owner without accounts": `[{"id": "a", "key_sha256": "` + hash + `", "role": "account-owner"}]`,
+		"duplicate key": `[{"id": "a", "key_sha256": "` + hash + `", "role": "admin"},
+			{"id": "b", "key_sha256": "` + hash + `", "role": "operator"}]`,
+		"unknown field": `[{"id": "a", "key": "secret", "role": "admin"}]`,
+	}
+	for name, file := range tests {
+		if _, err := ParseAPIKeys(strings.NewReader(file)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
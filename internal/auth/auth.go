@@ -0,0 +1,70 @@
+// Package auth authenticates HTTP callers. Authorization decisions are made
+// by the handler from the resulting domain.Principal.
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// Authenticator identifies the caller of a request. It returns a nil
+// principal and nil error when the request carries no credentials it
+// recognises, and an *Error when the credentials are present but invalid.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*domain.Principal, error)
+	// Challenge is the WWW-Authenticate value sent with 401 responses.
+	Challenge() string
+}
+
+// Chain tries each authenticator in turn and returns the first principal
+// found. Invalid credentials fail the request even if a later
+// authenticator would accept other credentials on it.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*domain.Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	return nil, nil
+}
+
+func (c Chain) Challenge() string {
+	challenges := make([]string, len(c))
+	for i, a := range c {
+		challenges[i] = a.Challenge()
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// Error is an authentication failure with a stable code clients can act
+// on. It matches domain.ErrUnauthenticated.
+type Error struct {
+	Code   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Reason
+}
+
+func (e *Error) Is(target error) bool {
+	return target == domain.ErrUnauthenticated
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller, or nil.
+func PrincipalFromContext(ctx context.Context) *domain.Principal {
+	p, _ := ctx.Value(ctxKey{}).(*domain.Principal)
+	return p
+}
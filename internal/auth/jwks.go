@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// jwk is a single JSON Web Key. Only the members needed for HS256 ("oct")
+// and RS256 ("RSA") verification are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// verificationKey is a parsed JWK bound to the one algorithm it may verify,
+// which rules out algorithm confusion between HMAC secrets and RSA keys.
+type verificationKey struct {
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+func parseJWKS(r io.Reader) (map[string]verificationKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, fmt.Errorf("parsing JWKS: %w", err)
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Kid == "" {
+			return nil, fmt.Errorf("key %d: missing kid", i)
+		}
+		if _, ok := keys[k.Kid]; ok {
+			return nil, fmt.Errorf("key %q: duplicate kid", k.Kid)
+		}
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) verificationKey() (verificationKey, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != "HS256" {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q for oct key", k.Alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) < 32 {
+			return verificationKey{}, errors.New("k must be at least 32 bytes of base64url")
+		}
+		return verificationKey{alg: "HS256", secret: secret}, nil
+	case "RSA":
+		if k.Alg != "" && k.Alg != "RS256" {
+			return verificationKey{}, fmt.Errorf("unsupported alg %q for RSA key", k.Alg)
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return verificationKey{}, errors.New("invalid n")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return verificationKey{}, errors.New("invalid e")
+		}
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if public.N.BitLen() < 2048 {
+			return verificationKey{}, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return verificationKey{alg: "RS256", public: public}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+}
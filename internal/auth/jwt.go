@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type JWTConfig struct {
+	// JWKSFile holds the verification keys. It is re-read when a token names
+	// an unknown kid and the file has changed since it was last loaded, so a
+	// key can be rotated in by adding it to the file before issuing tokens
+	// signed with it.
+	JWKSFile string
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// ClockSkew is the leeway allowed when checking exp and nbf.
+	ClockSkew time.Duration
+}
+
+// JWT authenticates requests by an "Authorization: Bearer" token signed
+// with HS256 or RS256. The sub claim becomes the principal ID, the accounts
+// claim its accounts, and the optional role claim its role, defaulting to
+// account-owner.
+type JWT struct {
+	cfg JWTConfig
+	now func() time.Time
+
+	keys    map[string]verificationKey
+	modTime time.Time
+	mu      sync.RWMutex
+}
+
+func NewJWT(cfg JWTConfig) (*JWT, error) {
+	j := &JWT{cfg: cfg, now: time.Now}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *JWT) Authenticate(r *http.Request) (*domain.Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+	claims, err := j.verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, &Error{Code: "invalid_token", Reason: err.Error()}
+	}
+	return claims.principal()
+}
+
+func (j *JWT) Challenge() string {
+	return "Bearer"
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  audience    `json:"aud"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+	Role      domain.Role `json:"role"`
+	Accounts  []string    `json:"accounts"`
+}
+
+// audience accepts the aud claim as either a string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+func (c jwtClaims) principal() (*domain.Principal, error) {
+	role := c.Role
+	if role == "" {
+		role = domain.RoleAccountOwner
+	}
+	if !role.Valid() {
+		return nil, &Error{Code: "invalid_token", Reason: fmt.Sprintf("unknown role %q", role)}
+	}
+	return &domain.Principal{ID: c.Subject, Role: role, Accounts: c.Accounts}, nil
+}
+
+func (j *JWT) verify(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	key, err := j.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != key.alg {
+		return nil, fmt.Errorf("alg %q does not match key %q", header.Alg, header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed signature")
+	}
+	if !key.verify(parts[0]+"."+parts[1], signature) {
+		return nil, errors.New("invalid signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("claims: %w", err)
+	}
+	if err := j.validate(claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (j *JWT) validate(c jwtClaims) error {
+	now := j.now()
+	if c.ExpiresAt == nil {
+		return errors.New("missing exp")
+	}
+	if now.After(unixTime(*c.ExpiresAt).Add(j.cfg.ClockSkew)) {
+		return errors.New("token expired")
+	}
+	if c.NotBefore != nil && now.Add(j.cfg.ClockSkew).Before(unixTime(*c.NotBefore)) {
+		return errors.New("token not yet valid")
+	}
+	if c.Subject == "" {
+		return errors.New("missing sub")
+	}
+	if j.cfg.Issuer != "" && c.Issuer != j.cfg.Issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if j.cfg.Audience != "" && !slices.Contains(c.Audience, j.cfg.Audience) {
+		return errors.New("token not issued for this audience")
+	}
+	return nil
+}
+
+func (k verificationKey) verify(signed string, signature []byte) bool {
+	switch k.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "RS256":
+		digest := sha256.Sum256([]byte(signed))
+		return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+// key returns the key for kid, reloading the JWKS file once if kid is
+// unknown and the file has changed.
+func (j *JWT) key(kid string) (verificationKey, error) {
+	if kid == "" {
+		return verificationKey{}, errors.New("missing kid")
+	}
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	j.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := j.load(); err != nil {
+		slog.Warn("Error reloading JWKS, keeping previous keys", "path", j.cfg.JWKSFile, "error", err)
+	}
+	j.mu.RLock()
+	key, ok = j.keys[kid]
+	j.mu.RUnlock()
+	if !ok {
+		return verificationKey{}, fmt.Errorf("unknown kid %q", kid)
+	}
+	return key, nil
+}
+
+// load reads the JWKS file unless it is unchanged since the last load.
+func (j *JWT) load() error {
+	info, err := os.Stat(j.cfg.JWKSFile)
+	if err != nil {
+		return err
+	}
+	j.mu.RLock()
+	unchanged := j.keys != nil && info.ModTime().Equal(j.modTime)
+	j.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(j.cfg.JWKSFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	keys, err := parseJWKS(f)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+	j.modTime = info.ModTime()
+	slog.Info("JWKS loaded", "path", j.cfg.JWKSFile, "keys", len(keys))
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed base64")
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
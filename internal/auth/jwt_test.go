@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signToken(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("Expected no error signing: %v", err)
+		}
+	}
+	return signed + "." + b64(sig)
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	t.Helper()
+
+	data, _ := json.Marshal(map[string]any{"keys": keys})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Expected no error writing JWKS: %v", err)
+	}
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "alg": "RS256", "use": "sig",
+		"n": b64(key.N.Bytes()),
+		"e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func octJWK(kid string, secret []byte) map[string]string {
+	return map[string]string{"kty": "oct", "kid": kid, "alg": "HS256", "k": b64(secret)}
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func newTestJWT(t *testing.T, cfg JWTConfig, keys ...map[string]string) (*JWT, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys...)
+	cfg.JWKSFile = path
+	j, err := NewJWT(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error loading JWKS: %v", err)
+	}
+	return j, path
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	j, _ := newTestJWT(t, JWTConfig{Issuer: "ipkiss-auth", Audience: "ipkiss"},
+		octJWK("h1", hmacSecret), rsaJWK("r1", rsaKey))
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for _, tc := range []struct {
+		alg, kid string
+		key      any
+	}{
+		{"HS256", "h1", hmacSecret},
+		{"RS256", "r1", rsaKey},
+	} {
+		token := signToken(t, tc.alg, tc.kid, tc.key, map[string]any{
+			"sub": "alice", "iss": "ipkiss-auth", "aud": []string{"ipkiss"}, "exp": exp, "accounts": []string{"100"},
+		})
+		p, err := j.Authenticate(bearerRequest(token))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.alg, err)
+		}
+		if p.ID != "alice" || p.Role != domain.RoleAccountOwner || !p.CanRead("100") || p.CanRead("200") {
+			t.Errorf("%s: expected alice owning 100, got %+v", tc.alg, p)
+		}
+	}
+}
+
+func TestJWT_Rejects(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	j, _ := newTestJWT(t, JWTConfig{Audience: "ipkiss", ClockSkew: time.Minute},
+		octJWK("h1", hmacSecret), rsaJWK("r1", rsaKey))
+	valid := func() map[string]any {
+		return map[string]any{"sub": "alice", "aud": "ipkiss", "exp": time.Now().Add(time.Hour).Unix()}
+	}
+	with := func(key string, value any) map[string]any {
+		claims := valid()
+		claims[key] = value
+		return claims
+	}
+
+	tests := map[string]string{
+		"expired past skew": signToken(t, "HS256", "h1", hmacSecret, with("exp", time.Now().Add(-2*time.Minute).Unix())),
+		"not yet valid":     signToken(t, "HS256", "h1", hmacSecret, with("nbf", time.Now().Add(2*time.Minute).Unix())),
+		"missing exp":       signToken(t, "HS256", "h1", hmacSecret, with("exp", nil)),
+		"wrong audience":    signToken(t, "HS256", "h1", hmacSecret, with("aud", "other")),
+		"unknown role":      signToken(t, "HS256", "h1", hmacSecret, with("role", "root")),
+		"wrong secret":      signToken(t, "HS256", "h1", []byte("another-secret-another-secret-!!"), valid()),
+		"unknown kid":       signToken(t, "HS256", "nope", hmacSecret, valid()),
+		"alg confusion":     signToken(t, "HS256", "r1", rsaKey.N.Bytes(), valid()),
+		"alg none":          signToken(t, "none", "h1", []byte{}, valid()),
+		"malformed":         "not.a.token.at.all",
+	}
+	for name, token := range tests {
+		_, err := j.Authenticate(bearerRequest(token))
+		if !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestJWT_ClockSkew(t *testing.T) {
+	j, _ := newTestJWT(t, JWTConfig{ClockSkew: time.Minute}, octJWK("h1", hmacSecret))
+
+	token := signToken(t, "HS256", "h1", hmacSecret, map[string]any{
+		"sub": "alice", "exp": time.Now().Add(-30 * time.Second).Unix(),
+	})
+	if _, err := j.Authenticate(bearerRequest(token)); err != nil {
+		t.Errorf("Expected token within clock skew to be accepted, got %v", err)
+	}
+}
+
+func TestJWT_KeyRotation(t *testing.T) {
+	j, path := newTestJWT(t, JWTConfig{}, octJWK("h1", hmacSecret))
+
+	newSecret := []byte("fedcba9876543210fedcba9876543210")
+	writeJWKS(t, path, octJWK("h1", hmacSecret), octJWK("h2", newSecret))
+	// Make sure the change is visible even on filesystems with coarse mtimes.
+	later := time.Now().Add(time.Second)
+	os.Chtimes(path, later, later)
+
+	token := signToken(t, "HS256", "h2", newSecret, map[string]any{
+		"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := j.Authenticate(bearerRequest(token)); err != nil {
+		t.Errorf("Expected token signed with rotated key to be accepted, got %v", err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	j, _ := newTestJWT(t, JWTConfig{}, octJWK("h1", hmacSecret))
+	keys, _ := NewAPIKeys([]APIKeyEntry{{ID: "ops", KeySHA256: HashAPIKey("ops-key"), Role: domain.RoleOperator}})
+	chain := Chain{keys, j}
+
+	req := bearerRequest(signToken(t, "HS256", "h1", hmacSecret, map[string]any{
+		"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(),
+	}))
+	if p, err := chain.Authenticate(req); err != nil || p == nil || p.ID != "alice" {
+		t.Errorf("Expected JWT principal through chain, got %v, %v", p, err)
+	}
+
+	req.Header.Set(APIKeyHeader, "wrong")
+	if _, err := chain.Authenticate(req); err == nil {
+		t.Errorf("Expected invalid API key to fail despite a valid token")
+	}
+
+	if got := chain.Challenge(); got != "ApiKey, Bearer" {
+		t.Errorf("Expected combined challenge, got %q", got)
+	}
+}
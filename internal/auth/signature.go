@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const (
+	SignatureHeader          = "X-Signature"
+	SignaturePartnerHeader   = "X-Signature-Partner"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+// Partner is a server-to-server caller sharing an HMAC secret with us.
+// Unlike API keys the secret itself must be stored, so the partners file
+// should be readable by the server only.
+type Partner struct {
+	ID       string      `json:"id"`
+	Secret   string      `json:"secret"`
+	Role     domain.Role `json:"role,omitempty"`
+	Accounts []string    `json:"accounts,omitempty"`
+}
+
+// Signatures authenticates requests signed with SignRequest. A request is
+// accepted once: its timestamp must lie within Window of the server clock
+// and its nonce must not have been seen in that time.
+type Signatures struct {
+	partners map[string]Partner
+	window   time.Duration
+	now      func() time.Time
+	nonces   *nonceCache
+}
+
+func NewSignatures(partners []Partner, window time.Duration) (*Signatures, error) {
+	s := &Signatures{
+		partners: make(map[string]Partner, len(partners)),
+		window:   window,
+		now:      time.Now,
+		nonces:   newNonceCache(),
+	}
+	for i, p := range partners {
+		if p.ID == "" {
+			return nil, fmt.Errorf("partner %d: missing id", i)
+		}
+		if len(p.Secret) < 32 {
+			return nil, fmt.Errorf("partner %q: secret must be at least 32 characters", p.ID)
+		}
+		if p.Role == "" {
+			p.Role = domain.RoleOperator
+		}
+		if !p.Role.Valid() {
+			return nil, fmt.Errorf("partner %q: unknown role %q", p.ID, p.Role)
+		}
+		if _, ok := s.partners[p.ID]; ok {
+			return nil, fmt.Errorf("partner %q: duplicate id", p.ID)
+		}
+		s.partners[p.ID] = p
+	}
+	return s, nil
+}
+
+// LoadSignatures reads a JSON array of Partner from path.
+func LoadSignatures(path string, window time.Duration) (*Signatures, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var partners []Partner
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&partners); err != nil {
+		return nil, fmt.Errorf("parsing partners: %w", err)
+	}
+	return NewSignatures(partners, window)
+}
+
+// Authenticate reads the whole body to verify it, then restores it for the
+// handler. Errors reading the body, such as exceeding the size limit, are
+// returned as they are rather than as authentication failures.
+func (s *Signatures) Authenticate(r *http.Request) (*domain.Principal, error) {
+	signature := r.Header.Get(SignatureHeader)
+	if signature == "" {
+		return nil, nil
+	}
+	partnerID := r.Header.Get(SignaturePartnerHeader)
+	timestamp := r.Header.Get(SignatureTimestampHeader)
+	nonce := r.Header.Get(SignatureNonceHeader)
+	if partnerID == "" || timestamp == "" || nonce == "" {
+		return nil, &Error{Code: "signature_headers_missing", Reason: "X-Signature requires partner, timestamp and nonce headers"}
+	}
+	partner, ok := s.partners[partnerID]
+	if !ok {
+		return nil, &Error{Code: "unknown_partner", Reason: "unknown partner " + strconv.Quote(partnerID)}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, &Error{Code: "invalid_timestamp", Reason: "timestamp must be Unix seconds"}
+	}
+	now := s.now()
+	if skew := now.Sub(time.Unix(unix, 0)).Abs(); skew > s.window {
+		return nil, &Error{Code: "timestamp_out_of_window", Reason: fmt.Sprintf("timestamp is %s from server time", skew.Truncate(time.Second))}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	want := SignRequest(partner.Secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return nil, &Error{Code: "invalid_signature", Reason: "signature does not match request"}
+	}
+	// Only verified requests use up a nonce, so forged requests cannot
+	// block a partner's legitimate ones.
+	if !s.nonces.add(partnerID+"\x00"+nonce, now.Add(2*s.window), now) {
+		return nil, &Error{Code: "nonce_reused", Reason: "nonce already used"}
+	}
+
+	return &domain.Principal{ID: partner.ID, Role: partner.Role, Accounts: partner.Accounts}, nil
+}
+
+func (s *Signatures) Challenge() string {
+	return "Signature"
+}
+
+// SignRequest returns the hex HMAC-SHA256 sent in X-Signature. It covers
+// the method, the path with query string, the timestamp, the nonce and the
+// SHA-256 of the body, one per line.
+func SignRequest(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", method, requestURI, timestamp, nonce, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// nonceCache remembers nonces until they expire. Entries only need to
+// outlive the timestamp window, after which the timestamp check rejects a
+// replay on its own.
+type nonceCache struct {
+	seen      map[string]time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time)}
+}
+
+// add records key and reports whether it was new.
+func (c *nonceCache) add(key string, expires, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) > time.Minute {
+		for k, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	if exp, ok := c.seen[key]; ok && !now.After(exp) {
+		return false
+	}
+	c.seen[key] = expires
+	return true
+}
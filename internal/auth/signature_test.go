@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const partnerSecret = "partner-secret-partner-secret-123"
+
+func newTestSignatures(t *testing.T) *Signatures {
+	t.Helper()
+
+	s, err := NewSignatures([]Partner{{ID: "acme", Secret: partnerSecret}}, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error creating signatures: %v", err)
+	}
+	return s
+}
+
+func signedRequest(secret string, ts time.Time, nonce, body string) *http.Request {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body))
+	req.Header.Set(SignaturePartnerHeader, "acme")
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, nonce)
+	req.Header.Set(SignatureHeader, SignRequest(secret, http.MethodPost, "/event", timestamp, nonce, []byte(body)))
+	return req
+}
+
+func authCode(err error) string {
+	var authErr *Error
+	if errors.As(err, &authErr) {
+		return authErr.Code
+	}
+	return ""
+}
+
+func TestSignatures_Authenticate(t *testing.T) {
+	s := newTestSignatures(t)
+	body := `{"type":"deposit","destination":"100","amount":10}`
+
+	req := signedRequest(partnerSecret, time.Now(), "n-1", body)
+	p, err := s.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if p.ID != "acme" || p.Role != domain.RoleOperator {
+		t.Errorf("Expected acme operator, got %+v", p)
+	}
+
+	restored, _ := io.ReadAll(req.Body)
+	if string(restored) != body {
+		t.Errorf("Expected body to be restored for the handler, got %q", restored)
+	}
+}
+
+func TestSignatures_Rejects(t *testing.T) {
+	s := newTestSignatures(t)
+	body := `{"type":"deposit","destination":"100","amount":10}`
+
+	s.Authenticate(signedRequest(partnerSecret, time.Now(), "used", body))
+
+	tampered := signedRequest(partnerSecret, time.Now(), "n-2", body)
+	tampered.Body = io.NopCloser(strings.NewReader(strings.Replace(body, "10", "1000", 1)))
+
+	unknown := signedRequest(partnerSecret, time.Now(), "n-3", body)
+	unknown.Header.Set(SignaturePartnerHeader, "globex")
+
+	missing := signedRequest(partnerSecret, time.Now(), "n-4", body)
+	missing.Header.Del(SignatureNonceHeader)
+
+	tests := map[string]struct {
+		req  *http.Request
+		code string
+	}{
+		"replayed nonce":   {signedRequest(partnerSecret, time.Now(), "used", body), "nonce_reused"},
+		"stale timestamp":  {signedRequest(partnerSecret, time.Now().Add(-10*time.Minute), "n-5", body), "timestamp_out_of_window"},
+		"future timestamp": {signedRequest(partnerSecret, time.Now().Add(10*time.Minute), "n-6", body), "timestamp_out_of_window"},
+		"wrong secret":     {signedRequest("another-secret-another-secret-12", time.Now(), "n-7", body), "invalid_signature"},
+		"tampered body":    {tampered, "invalid_signature"},
+		"unknown partner":  {unknown, "unknown_partner"},
+		"missing headers":  {missing, "signature_headers_missing"},
+	}
+	for name, tt := range tests {
+		_, err := s.Authenticate(tt.req)
+		if !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+		}
+		if code := authCode(err); code != tt.code {
+			t.Errorf("%s: expected code %s, got %q", name, tt.code, code)
+		}
+	}
+}
+
+func TestSignatures_ForgeryDoesNotBurnNonce(t *testing.T) {
+	s := newTestSignatures(t)
+
+	s.Authenticate(signedRequest("another-secret-another-secret-12", time.Now(), "n-1", "{}"))
+
+	if _, err := s.Authenticate(signedRequest(partnerSecret, time.Now(), "n-1", "{}")); err != nil {
+		t.Errorf("Expected genuine request to be accepted, got %v", err)
+	}
+}
+
+func TestNonceCache_Expires(t *testing.T) {
+	c := newNonceCache()
+	now := time.Now()
+
+	c.add("n", now.Add(time.Minute), now)
+	if c.add("n", now.Add(time.Minute), now) {
+		t.Errorf("Expected duplicate nonce to be rejected")
+	}
+	if !c.add("n", now.Add(3*time.Minute), now.Add(2*time.Minute)) {
+		t.Errorf("Expected expired nonce to be accepted again")
+	}
+}
@@ -0,0 +1,228 @@
+// Package conformance checks a server against the IPKISS test script, the
+// sequence of requests the assignment is graded with.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/handler"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+// Step is one request and the response it must get. Body is compared as
+// JSON when it is a JSON object, and as trimmed text otherwise.
+type Step struct {
+	Name   string
+	Method string
+	Path   string
+	Body   string
+	Status int
+	Want   string
+}
+
+// IPKISS is the official script. Each step depends on the ones before it,
+// so the steps must run in order against a server nobody else is using.
+var IPKISS = []Step{
+	{
+		Name:   "Reset state before starting tests",
+		Method: http.MethodPost, Path: "/reset",
+		Status: http.StatusOK, Want: "OK",
+	},
+	{
+		Name:   "Get balance for non-existing account",
+		Method: http.MethodGet, Path: "/balance?account_id=1234",
+		Status: http.StatusNotFound, Want: "0",
+	},
+	{
+		Name:   "Create account with initial balance",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"deposit", "destination":"100", "amount":10}`,
+		Status: http.StatusCreated, Want: `{"destination": {"id":"100", "balance":10}}`,
+	},
+	{
+		Name:   "Deposit into existing account",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"deposit", "destination":"100", "amount":10}`,
+		Status: http.StatusCreated, Want: `{"destination": {"id":"100", "balance":20}}`,
+	},
+	{
+		Name:   "Get balance for existing account",
+		Method: http.MethodGet, Path: "/balance?account_id=100",
+		Status: http.StatusOK, Want: "20",
+	},
+	{
+		Name:   "Withdraw from non-existing account",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"withdraw", "origin":"200", "amount":10}`,
+		Status: http.StatusNotFound, Want: "0",
+	},
+	{
+		Name:   "Withdraw from existing account",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"withdraw", "origin":"100", "amount":5}`,
+		Status: http.StatusCreated, Want: `{"origin": {"id":"100", "balance":15}}`,
+	},
+	{
+		Name:   "Transfer from existing account",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"transfer", "origin":"100", "amount":15, "destination":"300"}`,
+		Status: http.StatusCreated, Want: `{"origin": {"id":"100", "balance":0}, "destination": {"id":"300", "balance":15}}`,
+	},
+	{
+		Name:   "Transfer from non-existing account",
+		Method: http.MethodPost, Path: "/event",
+		Body:   `{"type":"transfer", "origin":"200", "amount":15, "destination":"300"}`,
+		Status: http.StatusNotFound, Want: "0",
+	},
+}
+
+// Result is the response one step got. Err is set when the request could
+// not be made at all.
+type Result struct {
+	Step   Step
+	Status int
+	Body   string
+	Err    error
+}
+
+// Passed reports whether the response matched the step.
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Status == r.Step.Status && sameBody(r.Step.Want, r.Body)
+}
+
+// Failure describes how the response differed, or is empty when it passed.
+func (r Result) Failure() string {
+	switch {
+	case r.Err != nil:
+		return r.Err.Error()
+	case r.Passed():
+		return ""
+	default:
+		return fmt.Sprintf("expected %d %s, got %d %s", r.Step.Status, r.Step.Want, r.Status, strings.TrimSpace(r.Body))
+	}
+}
+
+func sameBody(want, got string) bool {
+	want, got = strings.TrimSpace(want), strings.TrimSpace(got)
+	if !strings.HasPrefix(want, "{") {
+		return want == got
+	}
+	var w, g any
+	if json.Unmarshal([]byte(want), &w) != nil || json.Unmarshal([]byte(got), &g) != nil {
+		return false
+	}
+	wantJSON, _ := json.Marshal(w)
+	gotJSON, _ := json.Marshal(g)
+	return bytes.Equal(wantJSON, gotJSON)
+}
+
+// Runner sends steps to one server.
+type Runner struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type Option func(*Runner)
+
+// WithAPIKey authenticates every request with key.
+func WithAPIKey(key string) Option {
+	return func(r *Runner) {
+		r.apiKey = key
+	}
+}
+
+// WithHTTPClient sends requests through client instead of
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Runner) {
+		r.client = client
+	}
+}
+
+// WithHandler serves requests with h in-process, without a network.
+func WithHandler(h http.Handler) Option {
+	return WithHTTPClient(&http.Client{Transport: handlerTransport{h}})
+}
+
+// NewRunner sends steps to baseURL, which only needs a scheme and host when
+// WithHandler is used.
+func NewRunner(baseURL string, opts ...Option) *Runner {
+	r := &Runner{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run sends every step in order. A failed step does not stop the run,
+// though later steps usually fail with it.
+func (r *Runner) Run(ctx context.Context, steps []Step) []Result {
+	results := make([]Result, 0, len(steps))
+	for _, step := range steps {
+		res := Result{Step: step}
+		res.Status, res.Body, res.Err = r.send(ctx, step)
+		results = append(results, res)
+	}
+	return results
+}
+
+func (r *Runner) send(ctx context.Context, step Step) (int, string, error) {
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(step.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, r.baseURL+step.Path, body)
+	if err != nil {
+		return 0, "", err
+	}
+	if step.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, r.apiKey)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, "", err
+	}
+	return resp.StatusCode, string(data), nil
+}
+
+// handlerTransport answers requests by calling a handler directly.
+type handlerTransport struct {
+	h http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	t.h.ServeHTTP(w, req)
+	return w.Result(), nil
+}
+
+// NewHandler returns the HTTP API over a fresh in-memory repository, with
+// the services wired as the server wires them by default.
+func NewHandler() http.Handler {
+	accountService := service.NewAccountService(repository.NewInMemoryRepository())
+	eventService := service.NewEventService(accountService)
+	h := handler.NewAccountHTTPHandler(accountService, eventService)
+	eventService.AddListener(h)
+	return h.Handler()
+}
@@ -0,0 +1,101 @@
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func checkResults(t *testing.T, results []Result) {
+	t.Helper()
+	if len(results) != len(IPKISS) {
+		t.Fatalf("Expected %d results, got %d", len(IPKISS), len(results))
+	}
+	for _, res := range results {
+		if !res.Passed() {
+			t.Errorf("Expected %q to pass: %s", res.Step.Name, res.Failure())
+		}
+	}
+}
+
+func TestIPKISS_InProcess(t *testing.T) {
+	runner := NewRunner("http://ipkiss.test", WithHandler(NewHandler()))
+
+	checkResults(t, runner.Run(context.Background(), IPKISS))
+}
+
+func TestIPKISS_OverHTTP(t *testing.T) {
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+
+	// Running twice checks that the reset step really starts over.
+	runner := NewRunner(server.URL, WithHTTPClient(server.Client()))
+	checkResults(t, runner.Run(context.Background(), IPKISS))
+	checkResults(t, runner.Run(context.Background(), IPKISS))
+}
+
+// TestIPKISS_Live runs the script against a running server, for example
+// IPKISS_CONFORMANCE_URL=http://localhost:8080 go test ./internal/conformance.
+func TestIPKISS_Live(t *testing.T) {
+	url := os.Getenv("IPKISS_CONFORMANCE_URL")
+	if url == "" {
+		t.Skip("IPKISS_CONFORMANCE_URL not set")
+	}
+	runner := NewRunner(url, WithAPIKey(os.Getenv("IPKISS_API_KEY")))
+
+	checkResults(t, runner.Run(context.Background(), IPKISS))
+}
+
+func TestRun_DetectsRegressions(t *testing.T) {
+	// Answering 400 instead of 404 breaks every not-found step and nothing
+	// else.
+	api := NewHandler()
+	broken := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, r)
+		if rec.Code == http.StatusNotFound {
+			rec.Code = http.StatusBadRequest
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+	runner := NewRunner("http://ipkiss.test", WithHandler(broken))
+
+	for _, res := range runner.Run(context.Background(), IPKISS) {
+		if want := res.Step.Status != http.StatusNotFound; res.Passed() != want {
+			t.Errorf("Expected %q passed=%v, got %v (%s)", res.Step.Name, want, res.Passed(), res.Failure())
+		}
+	}
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	results := NewRunner(server.URL).Run(context.Background(), IPKISS[:1])
+	if results[0].Err == nil || results[0].Passed() || results[0].Failure() == "" {
+		t.Errorf("Expected an unreachable server to fail, got %+v", results[0])
+	}
+}
+
+func TestSameBody(t *testing.T) {
+	tests := []struct {
+		want, got string
+		same      bool
+	}{
+		{"0", "0", true},
+		{"OK", "OK\n", true},
+		{"20", "21", false},
+		{`{"destination": {"id":"100", "balance":10}}`, `{"destination":{"balance":10,"id":"100"}}` + "\n", true},
+		{`{"destination": {"id":"100", "balance":10}}`, `{"destination":{"id":"100","balance":10,"frozen":true}}`, false},
+		{`{"origin": {"id":"100", "balance":0}}`, `0`, false},
+		{`{"origin": {"id":"100", "balance":0}}`, `not json`, false},
+	}
+	for _, tt := range tests {
+		if got := sameBody(tt.want, tt.got); got != tt.same {
+			t.Errorf("Expected sameBody(%q, %q) to be %v", tt.want, tt.got, tt.same)
+		}
+	}
+}
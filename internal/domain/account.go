@@ -1,20 +1,96 @@
 package domain
 
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrAccountNotFound       = errors.New("Account not found")
+	ErrOriginAccountNotFound = errors.New("Origin account not found")
+	ErrInsufficientFunds     = errors.New("Insufficient funds")
+	ErrAccountFrozen         = errors.New("Account frozen")
+)
+
+const (
+	OutcomeSuccess           = "success"
+	OutcomeNotFound          = "not_found"
+	OutcomeInsufficientFunds = "insufficient_funds"
+	OutcomeFrozen            = "frozen"
+	OutcomeBlocked           = "blocked"
+	OutcomeDenied            = "denied"
+	OutcomeReview            = "review"
+	OutcomeCanceled          = "canceled"
+	OutcomeError             = "error"
+)
+
+// Outcome classifies the result of an account operation for metrics and
+// traces.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrOriginAccountNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, ErrInsufficientFunds):
+		return OutcomeInsufficientFunds
+	case errors.Is(err, ErrAccountFrozen):
+		return OutcomeFrozen
+	case errors.Is(err, ErrAccountBlocked):
+		return OutcomeBlocked
+	case errors.Is(err, ErrRiskDenied):
+		return OutcomeDenied
+	case errors.Is(err, ErrPendingReview):
+		return OutcomeReview
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	default:
+		return OutcomeError
+	}
+}
+
 type Account struct {
 	ID      string `json:"id"`
 	Balance int    `json:"balance"`
+	// Frozen accounts reject every balance change until unfrozen.
+	Frozen bool `json:"frozen,omitempty"`
 }
 
 type AccountService interface {
-	GetBalance(id string) (int, error)
-	Deposit(id string, amount int) (*Account, error)
-	Withdraw(id string, amount int) (*Account, error)
-	Transfer(originID, destinationID string, amount int) (origin, destination *Account, err error)
-	Reset() error
+	GetBalance(ctx context.Context, id string) (int, error)
+	Deposit(ctx context.Context, id string, amount int) (*Account, error)
+	Withdraw(ctx context.Context, id string, amount int) (*Account, error)
+	Transfer(ctx context.Context, originID, destinationID string, amount int) (origin, destination *Account, err error)
+	Reset(ctx context.Context) error
+	SetFrozen(ctx context.Context, id string, frozen bool) (*Account, error)
 }
 
 type AccountRepository interface {
+	FindByID(ctx context.Context, id string) (*Account, error)
+	Upsert(ctx context.Context, account *Account) (*Account, error)
+	Reset(ctx context.Context) error
+	// Transaction runs fn with exclusive access to the repository. Writes
+	// made through tx are applied together when fn returns nil and discarded
+	// otherwise. fn must only use tx, not the repository itself. Nothing is
+	// committed once ctx is done.
+	Transaction(ctx context.Context, fn func(tx AccountTx) error) error
+}
+
+// AccountTx is the view of the repository inside a transaction.
+type AccountTx interface {
 	FindByID(id string) (*Account, error)
 	Upsert(account *Account) (*Account, error)
-	Reset() error
+	// Accounts returns every account as the transaction sees it, sorted by
+	// ID.
+	Accounts() ([]Account, error)
+	// AppendOutbox stages messages to be published once the transaction
+	// commits.
+	AppendOutbox(messages ...OutboxMessage) error
+	// PostJournal stages a journal entry. Unbalanced entries are rejected
+	// with ErrUnbalancedEntry.
+	PostJournal(entry JournalEntry) error
+	// Clear stages removing every account and journal entry, as Reset does.
+	// Committed outbox messages stay pending; writes made after it in the
+	// same transaction are kept.
+	Clear() error
 }
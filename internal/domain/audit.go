@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditReset          = "reset"
+	AuditFreeze         = "account.freeze"
+	AuditUnfreeze       = "account.unfreeze"
+	AuditReviewApprove  = "review.approve"
+	AuditReviewReject   = "review.reject"
+	AuditWebhookCreate  = "webhook.register"
+	AuditWebhookDelete  = "webhook.delete"
+	AuditWebhookRetry   = "webhook.redeliver"
+	AuditScreeningBlock = "screening.block"
+	AuditImport         = "import"
+	AuditRestore        = "restore"
+)
+
+// AuditEntry is one link in the audit chain. Hash covers every other field,
+// including PrevHash, so editing or removing an entry breaks every later
+// link.
+type AuditEntry struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Actor    string          `json:"actor"`
+	Action   string          `json:"action"`
+	Target   string          `json:"target,omitempty"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	// AfterSeq skips entries up to and including this sequence number.
+	AfterSeq uint64
+	Limit    int
+}
+
+type AuditLog interface {
+	// Record appends an entry for the principal in ctx. Before and after are
+	// stored as JSON and may be nil.
+	Record(ctx context.Context, action, target string, before, after any) error
+	// Entries returns matching entries, oldest first.
+	Entries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrUnauthenticated = errors.New("Unauthenticated")
+	ErrForbidden       = errors.New("Forbidden")
+)
+
+type Role string
+
+const (
+	// RoleAdmin may do anything, including resetting state and freezing
+	// accounts.
+	RoleAdmin Role = "admin"
+	// RoleOperator may submit any event and read any balance.
+	RoleOperator Role = "operator"
+	// RoleAccountOwner may only read the balance of, and transfer out of,
+	// its own accounts.
+	RoleAccountOwner Role = "account-owner"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleOperator, RoleAccountOwner:
+		return true
+	}
+	return false
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	ID       string   `json:"id"`
+	Role     Role     `json:"role"`
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+// HasRole reports whether p holds one of roles. Admins hold every role.
+func (p *Principal) HasRole(roles ...Role) bool {
+	if p == nil {
+		return false
+	}
+	return p.Role == RoleAdmin || slices.Contains(roles, p.Role)
+}
+
+// CanRead reports whether p may read accountID.
+func (p *Principal) CanRead(accountID string) bool {
+	return p.HasRole(RoleOperator) || p.owns(accountID)
+}
+
+// CanSubmit reports whether p may submit event.
+func (p *Principal) CanSubmit(event EventRequest) bool {
+	if p.HasRole(RoleOperator) {
+		return true
+	}
+	return event.Type == "transfer" && p.owns(event.Origin)
+}
+
+func (p *Principal) owns(accountID string) bool {
+	return p != nil && p.Role == RoleAccountOwner && slices.Contains(p.Accounts, accountID)
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "context"
+
 type EventRequest struct {
 	Type        string `json:"type" validate:"required,oneof=deposit withdraw transfer"`
 	Origin      string `json:"origin,omitempty" validate:"omitempty,required_if=Type withdraw,required_if=Type transfer,numeric"`
@@ -12,6 +14,31 @@ type EventResponse struct {
 	Destination *Account `json:"destination,omitempty"`
 }
 
+// Clone returns a copy that does not share accounts with the receiver, so it
+// can be serialized after the accounts have moved on.
+func (r *EventResponse) Clone() *EventResponse {
+	if r == nil {
+		return nil
+	}
+	out := &EventResponse{}
+	if r.Origin != nil {
+		origin := *r.Origin
+		out.Origin = &origin
+	}
+	if r.Destination != nil {
+		destination := *r.Destination
+		out.Destination = &destination
+	}
+	return out
+}
+
 type EventService interface {
-	ProcessEvent(event EventRequest) (*EventResponse, error)
+	ProcessEvent(ctx context.Context, event EventRequest) (*EventResponse, error)
+}
+
+// EventListener is notified after an event has been successfully applied.
+// Implementations must not block, as they run on the caller's goroutine, and
+// must not retain ctx beyond the call.
+type EventListener interface {
+	OnEvent(ctx context.Context, event EventRequest, resp *EventResponse)
 }
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ImportEvents   = "events"
+	ImportAccounts = "accounts"
+)
+
+// ImportedEvent is an event read from line Line of an import.
+type ImportedEvent struct {
+	Line  int
+	Event EventRequest
+}
+
+// ImportedAccount is an account snapshot read from line Line of an import.
+// Importing it sets the account's balance and freeze outright.
+type ImportedAccount struct {
+	Line    int
+	Account Account
+}
+
+// MaxImportErrors bounds the line errors reported for one import.
+const MaxImportErrors = 100
+
+type ImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport describes an import. Nothing was applied when Errors is not
+// empty or DryRun is set.
+type ImportReport struct {
+	Kind    string            `json:"kind"`
+	DryRun  bool              `json:"dry_run"`
+	Records int               `json:"records"`
+	Applied bool              `json:"applied"`
+	Errors  []ImportLineError `json:"errors,omitempty"`
+}
+
+// Importer applies a whole import in one transaction. Any failing record
+// rolls it all back, and every failure up to MaxImportErrors is reported. A
+// dry run goes through the same steps and rolls back.
+type Importer interface {
+	ImportEvents(ctx context.Context, events []ImportedEvent, dryRun bool) (*ImportReport, error)
+	ImportAccounts(ctx context.Context, accounts []ImportedAccount, dryRun bool) (*ImportReport, error)
+}
+
+// ExportedEvent is a journal entry written back as the event that posted
+// it, so an export can be imported elsewhere.
+type ExportedEvent struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	EventRequest
+}
+
+// Exporter streams state to fn, one record at a time, as of the moment
+// the export started.
+type Exporter interface {
+	ExportAccounts(ctx context.Context, fn func(Account) error) error
+	ExportEvents(ctx context.Context, fn func(ExportedEvent) error) error
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrUnbalancedEntry = errors.New("Unbalanced journal entry")
+
+// CashAccount is the ledger account money enters and leaves the books
+// through. The "@" keeps it apart from customer account IDs, which are
+// numeric.
+const CashAccount = "@cash"
+
+// JournalLine posts an amount to one side of a ledger account. Exactly one
+// of Debit and Credit is set.
+type JournalLine struct {
+	Account string `json:"account"`
+	Debit   int    `json:"debit,omitempty"`
+	Credit  int    `json:"credit,omitempty"`
+}
+
+// JournalEntry records one operation as balanced lines. Customer accounts
+// are liabilities: credits raise their balance and debits lower it. Seq and
+// Time are assigned on commit; both only ever increase.
+type JournalEntry struct {
+	Seq   uint64        `json:"seq"`
+	Time  time.Time     `json:"time"`
+	Type  string        `json:"type"`
+	Lines []JournalLine `json:"lines"`
+}
+
+// Balanced reports whether e has lines, each posting a positive amount to a
+// single side, and whether its debits equal its credits.
+func (e JournalEntry) Balanced() bool {
+	if len(e.Lines) == 0 {
+		return false
+	}
+	debits, credits := 0, 0
+	for _, l := range e.Lines {
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit > 0) == (l.Credit > 0) {
+			return false
+		}
+		debits += l.Debit
+		credits += l.Credit
+	}
+	return debits == credits
+}
+
+// CustomerNet returns how much e changed the balance of a customer account.
+func (e JournalEntry) CustomerNet(account string) int {
+	net := 0
+	for _, l := range e.Lines {
+		if l.Account == account {
+			net += l.Credit - l.Debit
+		}
+	}
+	return net
+}
+
+type JournalRepository interface {
+	// Journal returns up to limit entries with a Seq above afterSeq, in Seq
+	// order.
+	Journal(ctx context.Context, afterSeq uint64, limit int) ([]JournalEntry, error)
+	// ListAccounts returns every account together with the Seq of the last
+	// journal entry they reflect, read at a single point in time.
+	ListAccounts(ctx context.Context) (accounts []Account, journalSeq uint64, err error)
+	// BalanceAt returns a customer account's balance after every entry
+	// committed at or before at. It reports false if the account had no
+	// entries by then.
+	BalanceAt(ctx context.Context, accountID string, at time.Time) (balance int, found bool, err error)
+	// Postings returns up to limit of a customer account's postings
+	// committed in [from, to) with a Seq above afterSeq, in Seq order.
+	Postings(ctx context.Context, accountID string, from, to time.Time, afterSeq uint64, limit int) ([]Posting, error)
+}
+
+// LedgerBalance is the total posted to one ledger account.
+type LedgerBalance struct {
+	Account string `json:"account"`
+	Debit   int    `json:"debit"`
+	Credit  int    `json:"credit"`
+}
+
+// BalanceMismatch is a customer account whose stored balance disagrees with
+// its ledger.
+type BalanceMismatch struct {
+	Account string `json:"account"`
+	Stored  int    `json:"stored"`
+	Ledger  int    `json:"ledger"`
+}
+
+type TrialBalance struct {
+	JournalSeq  uint64          `json:"journal_seq"`
+	Accounts    []LedgerBalance `json:"accounts"`
+	TotalDebit  int             `json:"total_debit"`
+	TotalCredit int             `json:"total_credit"`
+	// Balanced means total debits equal total credits.
+	Balanced bool `json:"balanced"`
+	// Mismatches lists stored balances that differ from the ledger.
+	Mismatches []BalanceMismatch `json:"mismatches,omitempty"`
+}
+
+// OK reports whether the books balance and match every stored balance.
+func (t *TrialBalance) OK() bool {
+	return t.Balanced && len(t.Mismatches) == 0
+}
+
+type LedgerService interface {
+	TrialBalance(ctx context.Context) (*TrialBalance, error)
+	// BalanceAsOf returns an account's balance at a past instant, or
+	// ErrAccountNotFound if it had no history by then.
+	BalanceAsOf(ctx context.Context, accountID string, at time.Time) (int, error)
+	// Statement streams an account's postings committed in [from, to) to w,
+	// or returns ErrAccountNotFound if the account has none before to.
+	Statement(ctx context.Context, accountID string, from, to time.Time, w StatementWriter) error
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event recorded in the same transaction as the balance
+// change it describes. Seq is assigned on commit and orders messages
+// globally; Accounts lists every account the event touched, which the relay
+// uses to keep delivery ordered per account.
+type OutboxMessage struct {
+	Seq       uint64         `json:"seq"`
+	ID        string         `json:"id"`
+	Accounts  []string       `json:"accounts"`
+	Event     EventRequest   `json:"event"`
+	Result    *EventResponse `json:"result"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type OutboxRepository interface {
+	// PendingOutbox returns up to limit unacknowledged messages with a Seq
+	// above after, in Seq order.
+	PendingOutbox(after uint64, limit int) ([]OutboxMessage, error)
+	// AckOutbox removes published messages from the outbox.
+	AckOutbox(seqs ...uint64) error
+}
+
+// Publisher delivers outbox messages to a downstream system. Messages may be
+// delivered more than once, so consumers should deduplicate on ID.
+type Publisher interface {
+	Publish(msg OutboxMessage) error
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRiskDenied     = errors.New("Denied by risk rules")
+	ErrPendingReview  = errors.New("Pending review")
+	ErrReviewNotFound = errors.New("Review not found")
+	ErrReviewDecided  = errors.New("Review already decided")
+)
+
+// RiskError reports an event stopped by the risk rules. Review is set when
+// the event was parked for review instead of denied outright.
+type RiskError struct {
+	Rule   string
+	Reason string
+	Review *Review
+}
+
+func (e *RiskError) Error() string {
+	if e.Review != nil {
+		return "Pending review " + e.Review.ID + ": " + e.Reason
+	}
+	return "Denied by " + e.Rule + ": " + e.Reason
+}
+
+func (e *RiskError) Is(target error) bool {
+	if e.Review != nil {
+		return target == ErrPendingReview
+	}
+	return target == ErrRiskDenied
+}
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+	// ReviewFailed means the event was approved but could not be applied,
+	// e.g. because the balance had changed in the meantime.
+	ReviewFailed ReviewStatus = "failed"
+)
+
+// Review is an event parked by the risk rules until an admin decides on it.
+type Review struct {
+	ID        string         `json:"id"`
+	Event     EventRequest   `json:"event"`
+	Rule      string         `json:"rule"`
+	Reason    string         `json:"reason"`
+	Status    ReviewStatus   `json:"status"`
+	CreatedAt time.Time      `json:"created_at"`
+	DecidedAt *time.Time     `json:"decided_at,omitempty"`
+	DecidedBy string         `json:"decided_by,omitempty"`
+	Note      string         `json:"note,omitempty"`
+	Result    *EventResponse `json:"result,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type ReviewService interface {
+	// Reviews lists reviews oldest first, filtered by status unless it is
+	// empty.
+	Reviews(ctx context.Context, status ReviewStatus) ([]Review, error)
+	// Approve applies the parked event, bypassing the rules that held it.
+	Approve(ctx context.Context, id, reviewer string) (*Review, error)
+	Reject(ctx context.Context, id, reviewer, note string) (*Review, error)
+}
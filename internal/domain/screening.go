@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrAccountBlocked = errors.New("Account blocked")
+
+// ScreeningError reports an event involving a screened account. Reason
+// comes from the list and is for compliance staff, not for callers.
+type ScreeningError struct {
+	AccountID string
+	Reason    string
+}
+
+func (e *ScreeningError) Error() string {
+	return "Account " + e.AccountID + " blocked by screening"
+}
+
+func (e *ScreeningError) Is(target error) bool {
+	return target == ErrAccountBlocked
+}
+
+// Screener checks the accounts involved in an event against a sanctions or
+// blocklist.
+type Screener interface {
+	// Screen returns a *ScreeningError when any account in event is listed.
+	Screen(ctx context.Context, event EventRequest) error
+}
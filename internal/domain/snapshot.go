@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SnapshotVersion is the snapshot format this build writes and restores.
+const SnapshotVersion = 1
+
+var (
+	ErrSnapshotVersion  = errors.New("Unsupported snapshot version")
+	ErrSnapshotChecksum = errors.New("Snapshot checksum mismatch")
+	ErrInvalidSnapshot  = errors.New("Invalid snapshot")
+)
+
+// Snapshot is every account as of one journal position.
+type Snapshot struct {
+	Version    int       `json:"version"`
+	TakenAt    time.Time `json:"taken_at"`
+	JournalSeq uint64    `json:"journal_seq"`
+	Accounts   []Account `json:"accounts"`
+	Checksum   string    `json:"checksum"`
+}
+
+// Sum returns the checksum of s, computed over its JSON encoding with
+// Checksum left empty.
+func (s Snapshot) Sum() string {
+	s.Checksum = ""
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Verify checks that s can be restored: a known version, an intact checksum
+// and unique accounts with non-negative balances.
+func (s Snapshot) Verify() error {
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, s.Version)
+	}
+	if s.Checksum != s.Sum() {
+		return ErrSnapshotChecksum
+	}
+	seen := make(map[string]bool, len(s.Accounts))
+	for _, a := range s.Accounts {
+		switch {
+		case a.ID == "":
+			return fmt.Errorf("%w: account without an ID", ErrInvalidSnapshot)
+		case seen[a.ID]:
+			return fmt.Errorf("%w: account %s appears twice", ErrInvalidSnapshot, a.ID)
+		case a.Balance < 0:
+			return fmt.Errorf("%w: negative balance for account %s", ErrInvalidSnapshot, a.ID)
+		}
+		seen[a.ID] = true
+	}
+	return nil
+}
+
+// SnapshotRepository is the storage snapshots are taken from and restored
+// to.
+type SnapshotRepository interface {
+	AccountRepository
+	JournalRepository
+}
+
+type SnapshotService interface {
+	Snapshot(ctx context.Context) (*Snapshot, error)
+	// Restore verifies s and replaces every account with it in one
+	// transaction.
+	Restore(ctx context.Context, s *Snapshot) error
+}
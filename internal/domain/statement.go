@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Posting is one journal entry as seen from a customer account. Amount is
+// signed: positive when money arrived. Counterparty is the other customer
+// account of a transfer.
+type Posting struct {
+	Seq          uint64    `json:"seq"`
+	Time         time.Time `json:"time"`
+	Type         string    `json:"type"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Amount       int       `json:"amount"`
+}
+
+// StatementLine is a posting with the account balance after it.
+type StatementLine struct {
+	Posting
+	Balance int `json:"balance"`
+}
+
+type StatementHeader struct {
+	AccountID string `json:"account_id"`
+	// From is zero when the statement starts at the account's first posting.
+	From           time.Time `json:"from,omitzero"`
+	To             time.Time `json:"to"`
+	OpeningBalance int       `json:"opening_balance"`
+}
+
+// StatementTotal sums one posting type. Credits raised the balance and
+// debits lowered it.
+type StatementTotal struct {
+	Type    string `json:"type"`
+	Count   int    `json:"count"`
+	Credits int    `json:"credits"`
+	Debits  int    `json:"debits"`
+}
+
+type StatementSummary struct {
+	Totals         []StatementTotal `json:"totals"`
+	ClosingBalance int              `json:"closing_balance"`
+}
+
+// StatementWriter receives a statement as it is produced: Begin once, Line
+// per posting in order, then End.
+type StatementWriter interface {
+	Begin(header StatementHeader) error
+	Line(line StatementLine) error
+	End(summary StatementSummary) error
+}
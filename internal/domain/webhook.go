@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound    = errors.New("Webhook not found")
+	ErrDeadLetterNotFound = errors.New("Dead letter not found")
+)
+
+// Webhook is a partner endpoint notified of processed events. Empty Accounts
+// or EventTypes match every account or event type respectively.
+type Webhook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url" validate:"required,http_url"`
+	Secret     string    `json:"secret,omitempty"`
+	Accounts   []string  `json:"accounts,omitempty" validate:"dive,numeric"`
+	EventTypes []string  `json:"event_types,omitempty" validate:"dive,oneof=deposit withdraw transfer"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook.
+type WebhookPayload struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Event     EventRequest   `json:"event"`
+	Result    *EventResponse `json:"result"`
+}
+
+// WebhookDelivery records a single delivery attempt.
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	PayloadID  string    `json:"payload_id"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	DurationMS int64     `json:"duration_ms"`
+	AttemptAt  time.Time `json:"attempted_at"`
+}
+
+// WebhookDeadLetter is a payload that exhausted its delivery attempts.
+type WebhookDeadLetter struct {
+	ID        string         `json:"id"`
+	WebhookID string         `json:"webhook_id"`
+	Payload   WebhookPayload `json:"payload"`
+	Attempts  int            `json:"attempts"`
+	LastError string         `json:"last_error"`
+	FailedAt  time.Time      `json:"failed_at"`
+}
+
+type WebhookService interface {
+	Register(hook Webhook) (*Webhook, error)
+	List() ([]Webhook, error)
+	Delete(id string) error
+	Deliveries(webhookID string) ([]WebhookDelivery, error)
+	DeadLetters() ([]WebhookDeadLetter, error)
+	Redeliver(deadLetterID string) error
+}
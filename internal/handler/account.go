@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func (h *HTTPHandler) registerAccountRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /accounts/{id}/freeze", h.requireRole(h.handleSetFrozen(true), domain.RoleAdmin))
+	mux.HandleFunc("POST /accounts/{id}/unfreeze", h.requireRole(h.handleSetFrozen(false), domain.RoleAdmin))
+}
+
+func (h *HTTPHandler) handleSetFrozen(frozen bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		account, err := h.accountService.SetFrozen(r.Context(), r.PathValue("id"), frozen)
+		if err != nil {
+			if writeContextError(w, r, err) {
+				return
+			}
+			if errors.Is(err, domain.ErrAccountNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, err.Error())
+				return
+			}
+			slog.ErrorContext(r.Context(), "Error changing account freeze", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(account)
+	}
+}
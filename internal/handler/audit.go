@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithAuditLog enables the audit query endpoint and records webhook changes.
+// Services record their own actions.
+func WithAuditLog(audit domain.AuditLog) Option {
+	return func(h *HTTPHandler) {
+		h.audit = audit
+	}
+}
+
+func (h *HTTPHandler) registerAuditRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /audit", h.requireRole(h.handleListAudit, domain.RoleAdmin))
+}
+
+func (h *HTTPHandler) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: q.Get("action"),
+		Target: q.Get("target"),
+	}
+	var err error
+	if v := q.Get("after"); v != "" {
+		if filter.AfterSeq, err = strconv.ParseUint(v, 10, 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid after")
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid limit")
+			return
+		}
+	}
+	entries, err := h.audit.Entries(r.Context(), filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// recordAudit logs, rather than reports, a failed write, since the action
+// it describes has already been taken.
+func (h *HTTPHandler) recordAudit(r *http.Request, action, target string, before, after any) {
+	if h.audit == nil {
+		return
+	}
+	if err := h.audit.Record(r.Context(), action, target, before, after); err != nil {
+		slog.ErrorContext(r.Context(), "Error recording audit entry", "action", action, "error", err)
+	}
+}
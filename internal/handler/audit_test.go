@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/audit"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestListAudit(t *testing.T) {
+	log := audit.NewLog()
+	mockWebhooks := &MockWebhookService{
+		DeleteFunc: func(id string) error { return nil },
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithWebhookService(mockWebhooks), WithAuditLog(log))
+	routes := h.routes()
+
+	routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/webhooks/wh_1", nil))
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?action=webhook.delete", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var entries []domain.AuditEntry
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	if len(entries) != 1 || entries[0].Target != "wh_1" {
+		t.Errorf("Expected the webhook deletion to be recorded, got %+v", entries)
+	}
+}
+
+func TestListAudit_InvalidLimit(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithAuditLog(audit.NewLog()))
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?limit=0", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
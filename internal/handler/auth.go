@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithAuthenticator requires callers of account, admin and webhook routes to
+// authenticate, and enforces their roles. Use auth.Chain to accept several
+// credential types. Without it every route is open.
+// Health, version and metrics routes stay public for probes and scrapers.
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return func(h *HTTPHandler) {
+		h.authenticator = authenticator
+	}
+}
+
+// WithProductionMode removes the /reset route.
+func WithProductionMode() Option {
+	return func(h *HTTPHandler) {
+		h.production = true
+	}
+}
+
+// authenticate stores the caller's principal in the request context.
+// Requests without credentials pass through anonymously and are rejected by
+// the routes that need a principal; invalid credentials are rejected here.
+func (h *HTTPHandler) authenticate(next http.Handler) http.Handler {
+	if h.authenticator == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Failed attempts are charged to the caller's IP, and an IP that has
+		// used up its bucket is refused before its credentials are checked,
+		// so guessing is throttled before any principal is known.
+		ip := remoteKey(r)
+		if !slices.Contains(rateLimitExempt, r.URL.Path) {
+			if d := h.clientLimiter.Peek(ip); !d.Allowed {
+				slog.InfoContext(r.Context(), "Client rate limited", "client", ip)
+				writeRateLimitHeaders(w, d)
+				writeRateLimited(w, d)
+				return
+			}
+		}
+		principal, err := h.authenticator.Authenticate(r)
+		if err != nil {
+			if !errors.Is(err, domain.ErrUnauthenticated) {
+				writeDecodeError(w, err)
+				return
+			}
+			slog.InfoContext(r.Context(), "Authentication failed", "error", err)
+			h.clientLimiter.Allow(ip)
+			h.writeAuthError(w, err)
+			return
+		}
+		if principal == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		serveWithContext(next, w, r, auth.WithPrincipal(r.Context(), principal))
+	})
+}
+
+// requireRole only lets callers holding one of roles reach next.
+func (h *HTTPHandler) requireRole(next http.HandlerFunc, roles ...domain.Role) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.authorize(r.Context(), func(p *domain.Principal) bool {
+			return p.HasRole(roles...)
+		})
+		if err != nil {
+			h.writeAuthError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authorize checks the caller in ctx against allowed. It always succeeds
+// when authentication is disabled.
+func (h *HTTPHandler) authorize(ctx context.Context, allowed func(*domain.Principal) bool) error {
+	if h.authenticator == nil {
+		return nil
+	}
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.ErrUnauthenticated
+	}
+	if !allowed(principal) {
+		slog.InfoContext(ctx, "Request forbidden", "principal", principal.ID, "role", principal.Role)
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// writeAuthError answers 401 with a JSON body holding a stable error code,
+// or 403 for authenticated callers lacking permission.
+func (h *HTTPHandler) writeAuthError(w http.ResponseWriter, err error) {
+	if !errors.Is(err, domain.ErrUnauthenticated) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "forbidden")
+		return
+	}
+	authErr := &auth.Error{Code: "credentials_missing", Reason: "no credentials provided"}
+	errors.As(err, &authErr)
+	w.Header().Set("WWW-Authenticate", h.authenticator.Challenge())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"code": authErr.Code, "error": authErr.Reason})
+}
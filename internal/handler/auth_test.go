@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func newAuthTestHandler(t *testing.T, opts ...Option) *HTTPHandler {
+	t.Helper()
+
+	keys, err := auth.NewAPIKeys([]auth.APIKeyEntry{
+		{ID: "root", KeySHA256: auth.HashAPIKey("admin-key"), Role: domain.RoleAdmin},
+		{ID: "ops", KeySHA256: auth.HashAPIKey("operator-key"), Role: domain.RoleOperator},
+		{ID: "alice", KeySHA256: auth.HashAPIKey("owner-key"), Role: domain.RoleAccountOwner, Accounts: []string{"100"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error creating keys: %v", err)
+	}
+	mockSvc := &MockService{
+		BalanceFunc: func(id string) (int, error) {
+			return 10, nil
+		},
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return &domain.EventResponse{}, nil
+		},
+		SetFrozenFunc: func(id string, frozen bool) (*domain.Account, error) {
+			return &domain.Account{ID: id, Frozen: frozen}, nil
+		},
+	}
+	opts = append(opts, WithAuthenticator(keys))
+	return NewAccountHTTPHandler(mockSvc, mockSvc, opts...)
+}
+
+func serveWithKey(h *HTTPHandler, method, target, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(auth.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, req)
+	return w
+}
+
+func TestAuth_Roles(t *testing.T) {
+	h := newAuthTestHandler(t)
+	deposit := `{"type":"deposit", "destination":"100", "amount":10}`
+	ownTransfer := `{"type":"transfer", "origin":"100", "destination":"200", "amount":10}`
+	otherTransfer := `{"type":"transfer", "origin":"200", "destination":"100", "amount":10}`
+
+	tests := []struct {
+		name, method, target, body, key string
+		want                            int
+	}{
+		{"no key", http.MethodGet, "/balance?account_id=100", "", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/healthz", "", "nope", http.StatusUnauthorized},
+		{"health is public", http.MethodGet, "/healthz", "", "", http.StatusOK},
+		{"owner reads own balance", http.MethodGet, "/balance?account_id=100", "", "owner-key", http.StatusOK},
+		{"owner reads other balance", http.MethodGet, "/balance?account_id=200", "", "owner-key", http.StatusForbidden},
+		{"owner transfers out", http.MethodPost, "/event", ownTransfer, "owner-key", http.StatusCreated},
+		{"owner transfers from other", http.MethodPost, "/event", otherTransfer, "owner-key", http.StatusForbidden},
+		{"owner deposits", http.MethodPost, "/event", deposit, "owner-key", http.StatusForbidden},
+		{"operator deposits", http.MethodPost, "/event", deposit, "operator-key", http.StatusCreated},
+		{"operator resets", http.MethodPost, "/reset", "", "operator-key", http.StatusForbidden},
+		{"operator freezes", http.MethodPost, "/accounts/100/freeze", "", "operator-key", http.StatusForbidden},
+		{"admin resets", http.MethodPost, "/reset", "", "admin-key", http.StatusOK},
+		{"admin freezes", http.MethodPost, "/accounts/100/freeze", "", "admin-key", http.StatusOK},
+		{"admin deposits", http.MethodPost, "/event", deposit, "admin-key", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		w := serveWithKey(h, tt.method, tt.target, tt.body, tt.key)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestAuth_UnauthenticatedChallenge(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	w := serveWithKey(h, http.MethodPost, "/reset", "", "")
+
+	if w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected WWW-Authenticate header on 401")
+	}
+}
+
+func TestAuth_ProductionDisablesReset(t *testing.T) {
+	h := newAuthTestHandler(t, WithProductionMode())
+
+	w := serveWithKey(h, http.MethodPost, "/reset", "", "admin-key")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestHandleEvent_FrozenAccount(t *testing.T) {
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return nil, domain.ErrAccountFrozen
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc)
+
+	body := []byte(`{"type":"withdraw", "origin":"100", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestAuth_SignedRequest(t *testing.T) {
+	const secret = "partner-secret-partner-secret-123"
+	signatures, _ := auth.NewSignatures([]auth.Partner{{ID: "acme", Secret: secret}}, time.Minute)
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return &domain.EventResponse{Destination: &domain.Account{ID: req.Destination, Balance: req.Amount}}, nil
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc, WithAuthenticator(signatures))
+
+	send := func(nonce string) *httptest.ResponseRecorder {
+		body := `{"type":"deposit", "destination":"100", "amount":10}`
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body))
+		req.Header.Set(auth.SignaturePartnerHeader, "acme")
+		req.Header.Set(auth.SignatureTimestampHeader, timestamp)
+		req.Header.Set(auth.SignatureNonceHeader, nonce)
+		req.Header.Set(auth.SignatureHeader, auth.SignRequest(secret, http.MethodPost, "/event", timestamp, nonce, []byte(body)))
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("n-1"); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := send("n-1")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401 for replay, got %d", w.Code)
+	}
+	var body map[string]string
+	json.NewDecoder(w.Body).Decode(&body)
+	if body["code"] != "nonce_reused" {
+		t.Errorf("Expected code nonce_reused, got %v", body)
+	}
+}
+
+func TestAuth_SignedRequestBodyTooLarge(t *testing.T) {
+	signatures, _ := auth.NewSignatures([]auth.Partner{{ID: "acme", Secret: "partner-secret-partner-secret-123"}}, time.Minute)
+	cfg := DefaultServerConfig()
+	cfg.MaxBodyBytes = 16
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithAuthenticator(signatures), WithServerConfig(cfg))
+
+	req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(`{"type":"deposit", "destination":"100", "amount":10}`))
+	req.Header.Set(auth.SignaturePartnerHeader, "acme")
+	req.Header.Set(auth.SignatureTimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	req.Header.Set(auth.SignatureNonceHeader, "n-1")
+	req.Header.Set(auth.SignatureHeader, "deadbeef")
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
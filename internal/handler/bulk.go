@@ -0,0 +1,395 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+var (
+	eventColumns   = []string{"seq", "time", "type", "origin", "destination", "amount"}
+	accountColumns = []string{"id", "balance", "frozen"}
+)
+
+// WithImporter enables POST /admin/import.
+func WithImporter(importer domain.Importer) Option {
+	return func(h *HTTPHandler) {
+		h.importer = importer
+	}
+}
+
+// WithExporter enables GET /admin/export.
+func WithExporter(exporter domain.Exporter) Option {
+	return func(h *HTTPHandler) {
+		h.exporter = exporter
+	}
+}
+
+func (h *HTTPHandler) registerBulkRoutes(mux *http.ServeMux) {
+	if h.importer != nil {
+		mux.HandleFunc("POST /admin/import", h.requireRole(h.handleImport, domain.RoleAdmin))
+	}
+	if h.exporter != nil {
+		mux.HandleFunc("GET /admin/export", h.requireRole(h.handleExport, domain.RoleAdmin))
+	}
+}
+
+// handleImport validates every record before handing the batch to the
+// importer, so a file with mistakes is reported in full and changes nothing.
+func (h *HTTPHandler) handleImport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	kind, ok := bulkKind(query.Get("kind"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid kind")
+		return
+	}
+	dryRun := false
+	if v := query.Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid dry_run")
+			return
+		}
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "ndjson"
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+			format = "csv"
+		}
+	}
+	if format != "ndjson" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid format")
+		return
+	}
+
+	var (
+		report *domain.ImportReport
+		err    error
+	)
+	if kind == domain.ImportEvents {
+		var events []domain.ImportedEvent
+		var lineErrs []domain.ImportLineError
+		events, lineErrs, err = h.readImportedEvents(r.Body, format)
+		if err == nil && len(lineErrs) > 0 {
+			report = &domain.ImportReport{Kind: kind, DryRun: dryRun, Records: len(events), Errors: lineErrs}
+		} else if err == nil {
+			report, err = h.importer.ImportEvents(r.Context(), events, dryRun)
+		}
+	} else {
+		var accounts []domain.ImportedAccount
+		var lineErrs []domain.ImportLineError
+		accounts, lineErrs, err = h.readImportedAccounts(r.Body, format)
+		if err == nil && len(lineErrs) > 0 {
+			report = &domain.ImportReport{Kind: kind, DryRun: dryRun, Records: len(accounts), Errors: lineErrs}
+		} else if err == nil {
+			report, err = h.importer.ImportAccounts(r.Context(), accounts, dryRun)
+		}
+	}
+	if err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeDecodeError(w, err)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error importing", "kind", kind, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *HTTPHandler) readImportedEvents(body io.Reader, format string) ([]domain.ImportedEvent, []domain.ImportLineError, error) {
+	var events []domain.ImportedEvent
+	lineErrs, err := readImport(body, format, eventColumns, func(line int, data []byte, row map[string]string) error {
+		var e domain.ExportedEvent
+		if data != nil {
+			if err := decodeStrict(data, &e); err != nil {
+				return err
+			}
+		} else {
+			e.Type, e.Origin, e.Destination = row["type"], row["origin"], row["destination"]
+			amount, err := strconv.Atoi(row["amount"])
+			if err != nil {
+				return errors.New("invalid amount")
+			}
+			e.Amount = amount
+		}
+		if err := h.validate.Struct(e.EventRequest); err != nil {
+			return validationMessage(err)
+		}
+		events = append(events, domain.ImportedEvent{Line: line, Event: e.EventRequest})
+		return nil
+	})
+	return events, lineErrs, err
+}
+
+func (h *HTTPHandler) readImportedAccounts(body io.Reader, format string) ([]domain.ImportedAccount, []domain.ImportLineError, error) {
+	var accounts []domain.ImportedAccount
+	lineErrs, err := readImport(body, format, accountColumns, func(line int, data []byte, row map[string]string) error {
+		var a domain.Account
+		if data != nil {
+			if err := decodeStrict(data, &a); err != nil {
+				return err
+			}
+		} else {
+			a.ID = row["id"]
+			balance, err := strconv.Atoi(row["balance"])
+			if err != nil {
+				return errors.New("invalid balance")
+			}
+			a.Balance = balance
+			if v := row["frozen"]; v != "" {
+				if a.Frozen, err = strconv.ParseBool(v); err != nil {
+					return errors.New("invalid frozen")
+				}
+			}
+		}
+		if err := h.validate.Var(a.ID, "required,numeric"); err != nil {
+			return errors.New("id must be numeric")
+		}
+		if a.Balance < 0 {
+			return errors.New("balance must not be negative")
+		}
+		accounts = append(accounts, domain.ImportedAccount{Line: line, Account: a})
+		return nil
+	})
+	return accounts, lineErrs, err
+}
+
+// readImport calls fn for every record in body with its line number. NDJSON
+// records come as data; CSV records come as row, keyed by the header, which
+// may only name columns. An error from fn becomes that line's error. The
+// returned error is for a body that could not be read at all.
+func readImport(body io.Reader, format string, columns []string, fn func(line int, data []byte, row map[string]string) error) ([]domain.ImportLineError, error) {
+	var lineErrs []domain.ImportLineError
+	report := func(line int, err error) bool {
+		lineErrs = append(lineErrs, domain.ImportLineError{Line: line, Error: err.Error()})
+		return len(lineErrs) < domain.MaxImportErrors
+	}
+
+	if format == "ndjson" {
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			if err := fn(line, data, nil); err != nil && !report(line, err) {
+				break
+			}
+		}
+		return lineErrs, scanner.Err()
+	}
+
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return csvError(err, lineErrs)
+	}
+	for _, name := range header {
+		if !slices.Contains(columns, name) {
+			report(1, fmt.Errorf("unknown column %q", name))
+			return lineErrs, nil
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return lineErrs, nil
+		}
+		if err != nil {
+			return csvError(err, lineErrs)
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = strings.TrimSpace(record[i])
+		}
+		line, _ := reader.FieldPos(0)
+		if err := fn(line, nil, row); err != nil && !report(line, err) {
+			return lineErrs, nil
+		}
+	}
+}
+
+// csvError reports a malformed CSV record as a line error. Reading stops
+// there, since later line numbers can no longer be trusted.
+func csvError(err error, lineErrs []domain.ImportLineError) ([]domain.ImportLineError, error) {
+	var parseErr *csv.ParseError
+	if !errors.As(err, &parseErr) {
+		return lineErrs, err
+	}
+	return append(lineErrs, domain.ImportLineError{Line: parseErr.Line, Error: parseErr.Err.Error()}), nil
+}
+
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// validationMessage joins the English validation messages for one record.
+func validationMessage(err error) error {
+	var msgs []string
+	for _, t := range translateValidationErrors(err, "en") {
+		for _, msg := range t {
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) == 0 {
+		return err
+	}
+	slices.Sort(msgs)
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+func bulkKind(v string) (string, bool) {
+	switch v {
+	case "", domain.ImportEvents:
+		return domain.ImportEvents, true
+	case domain.ImportAccounts:
+		return domain.ImportAccounts, true
+	default:
+		return "", false
+	}
+}
+
+// handleExport streams every account or every journal entry, in the same
+// formats handleImport reads.
+func (h *HTTPHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	kind, ok := bulkKind(query.Get("kind"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid kind")
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "ndjson"
+	}
+	contentType := "application/x-ndjson"
+	switch format {
+	case "ndjson":
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid format")
+		return
+	}
+
+	stream := newStreamResponse(w, contentType, h.serverConfig.WriteTimeout)
+	out := newExportWriter(w, format)
+	var err error
+	if kind == domain.ImportEvents {
+		out.header(eventColumns)
+		err = h.exporter.ExportEvents(r.Context(), func(e domain.ExportedEvent) error {
+			stream.start()
+			return out.record(stream, e, []string{
+				strconv.FormatUint(e.Seq, 10),
+				e.Time.Format(time.RFC3339Nano),
+				e.Type,
+				e.Origin,
+				e.Destination,
+				strconv.Itoa(e.Amount),
+			})
+		})
+	} else {
+		out.header(accountColumns)
+		err = h.exporter.ExportAccounts(r.Context(), func(a domain.Account) error {
+			stream.start()
+			return out.record(stream, a, []string{a.ID, strconv.Itoa(a.Balance), strconv.FormatBool(a.Frozen)})
+		})
+	}
+	if err == nil {
+		stream.start()
+		err = out.flush()
+	}
+	if err == nil {
+		return
+	}
+	if stream.started {
+		stream.abort(r, "Export aborted", "kind", kind, "error", err)
+	}
+	if writeContextError(w, r, err) {
+		return
+	}
+	slog.ErrorContext(r.Context(), "Error exporting", "kind", kind, "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// exportWriter writes records as NDJSON or as CSV rows. Nothing reaches the
+// client before the first flush, so the CSV header can be queued before the
+// response starts.
+type exportWriter struct {
+	enc *json.Encoder
+	csv *csv.Writer
+	buf *bufio.Writer
+}
+
+func newExportWriter(w io.Writer, format string) *exportWriter {
+	buf := bufio.NewWriter(w)
+	if format == "csv" {
+		return &exportWriter{csv: csv.NewWriter(buf), buf: buf}
+	}
+	return &exportWriter{enc: json.NewEncoder(buf), buf: buf}
+}
+
+func (x *exportWriter) header(columns []string) {
+	if x.csv != nil {
+		x.csv.Write(columns)
+	}
+}
+
+func (x *exportWriter) record(stream *streamResponse, v any, row []string) error {
+	var err error
+	if x.csv != nil {
+		err = x.csv.Write(row)
+	} else {
+		err = x.enc.Encode(v)
+	}
+	if err != nil {
+		return err
+	}
+	return stream.written(x.flush)
+}
+
+func (x *exportWriter) flush() error {
+	if x.csv != nil {
+		x.csv.Flush()
+		if err := x.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return x.buf.Flush()
+}
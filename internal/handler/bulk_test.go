@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type MockImporter struct {
+	ImportEventsFunc   func([]domain.ImportedEvent, bool) (*domain.ImportReport, error)
+	ImportAccountsFunc func([]domain.ImportedAccount, bool) (*domain.ImportReport, error)
+}
+
+func (m *MockImporter) ImportEvents(ctx context.Context, events []domain.ImportedEvent, dryRun bool) (*domain.ImportReport, error) {
+	return m.ImportEventsFunc(events, dryRun)
+}
+
+func (m *MockImporter) ImportAccounts(ctx context.Context, accounts []domain.ImportedAccount, dryRun bool) (*domain.ImportReport, error) {
+	return m.ImportAccountsFunc(accounts, dryRun)
+}
+
+type MockExporter struct {
+	Accounts []domain.Account
+	Events   []domain.ExportedEvent
+	Err      error
+}
+
+func (m *MockExporter) ExportAccounts(ctx context.Context, fn func(domain.Account) error) error {
+	for _, a := range m.Accounts {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return m.Err
+}
+
+func (m *MockExporter) ExportEvents(ctx context.Context, fn func(domain.ExportedEvent) error) error {
+	for _, e := range m.Events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return m.Err
+}
+
+func TestImport_Events(t *testing.T) {
+	var got []domain.ImportedEvent
+	var gotDryRun bool
+	importer := &MockImporter{
+		ImportEventsFunc: func(events []domain.ImportedEvent, dryRun bool) (*domain.ImportReport, error) {
+			got, gotDryRun = events, dryRun
+			return &domain.ImportReport{Kind: domain.ImportEvents, DryRun: dryRun, Records: len(events)}, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithImporter(importer))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"ndjson", "application/x-ndjson", `{"type":"deposit","destination":"100","amount":10}
+
+{"seq":7,"time":"2026-10-01T00:00:00Z","type":"transfer","origin":"100","destination":"300","amount":4}
+`},
+		{"csv", "text/csv", "type,origin,destination,amount\ndeposit,,100,10\ntransfer,100,300,4\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/admin/import?dry_run=true", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || !gotDryRun {
+			t.Errorf("Expected a 200 dry run for %s, got %d: %s", tt.name, w.Code, w.Body.String())
+		}
+		want := domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 4}
+		if len(got) != 2 || got[1].Event != want || got[1].Line != 3 {
+			t.Errorf("Expected the transfer on line 3 for %s, got %+v", tt.name, got)
+		}
+	}
+}
+
+func TestImport_LineErrors(t *testing.T) {
+	importer := &MockImporter{
+		ImportEventsFunc: func([]domain.ImportedEvent, bool) (*domain.ImportReport, error) {
+			t.Error("Expected an invalid file not to reach the importer")
+			return nil, nil
+		},
+		ImportAccountsFunc: func([]domain.ImportedAccount, bool) (*domain.ImportReport, error) {
+			t.Error("Expected an invalid file not to reach the importer")
+			return nil, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithImporter(importer))
+
+	tests := []struct {
+		query     string
+		body      string
+		wantLines []int
+	}{
+		{"kind=events", `{"type":"deposit","destination":"100","amount":10}
+{"type":"deposit","destination":"100","amount":0}
+{"type":"loan","destination":"100","amount":1}
+{"type":"deposit","destination":"100","amount":1,"note":"x"}
+`, []int{2, 3, 4}},
+		{"kind=events&format=csv", "type,destination,amount\ndeposit,100,ten\ndeposit,100,1\n", []int{2}},
+		{"kind=events&format=csv", "type,memo\n", []int{1}},
+		{"kind=accounts&format=csv", "id,balance,frozen\n100,5,maybe\n200,-1,\nabc,1,false\n300,1,true\n", []int{2, 3, 4}},
+		{"kind=accounts", `{"id":"100","balance":5}` + "\n" + `{"id":"","balance":5}`, []int{2}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/import?"+tt.query, strings.NewReader(tt.body)))
+
+		var report domain.ImportReport
+		json.Unmarshal(w.Body.Bytes(), &report)
+		var lines []int
+		for _, e := range report.Errors {
+			lines = append(lines, e.Line)
+		}
+		if w.Code != http.StatusUnprocessableEntity || len(lines) != len(tt.wantLines) {
+			t.Errorf("Expected 422 with errors on lines %v for %s, got %d %+v", tt.wantLines, tt.query, w.Code, report.Errors)
+			continue
+		}
+		for i := range lines {
+			if lines[i] != tt.wantLines[i] {
+				t.Errorf("Expected errors on lines %v for %s, got %+v", tt.wantLines, tt.query, report.Errors)
+				break
+			}
+		}
+	}
+}
+
+func TestImport_ApplyError(t *testing.T) {
+	importer := &MockImporter{
+		ImportEventsFunc: func(events []domain.ImportedEvent, dryRun bool) (*domain.ImportReport, error) {
+			return &domain.ImportReport{Kind: domain.ImportEvents, Records: len(events), Errors: []domain.ImportLineError{{Line: 1, Error: "Insufficient funds"}}}, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithImporter(importer))
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/import", strings.NewReader(`{"type":"withdraw","origin":"100","amount":10}`)))
+
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "Insufficient funds") {
+		t.Errorf("Expected 422 with the failing line, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestImport_BulkBodyLimit(t *testing.T) {
+	importer := &MockImporter{
+		ImportEventsFunc: func(events []domain.ImportedEvent, dryRun bool) (*domain.ImportReport, error) {
+			return &domain.ImportReport{Kind: domain.ImportEvents, Records: len(events), Applied: true}, nil
+		},
+	}
+	cfg := DefaultServerConfig()
+	cfg.MaxBodyBytes = 64
+	cfg.MaxBulkBodyBytes = 1024
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithImporter(importer), WithServerConfig(cfg))
+	line := `{"type":"deposit","destination":"100","amount":10}` + "\n"
+
+	tests := []struct {
+		lines int
+		want  int
+	}{
+		{lines: 4, want: http.StatusOK},
+		{lines: 40, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/import", strings.NewReader(strings.Repeat(line, tt.lines))))
+
+		if w.Code != tt.want {
+			t.Errorf("Expected %d for %d lines, got %d", tt.want, tt.lines, w.Code)
+		}
+	}
+}
+
+func TestImport_BadParams(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithImporter(&MockImporter{}))
+
+	for _, query := range []string{"kind=people", "dry_run=perhaps", "format=xml"} {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/import?"+query, strings.NewReader("")))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	exporter := &MockExporter{
+		Accounts: []domain.Account{{ID: "100", Balance: 30}, {ID: "200", Balance: 0, Frozen: true}},
+		Events: []domain.ExportedEvent{
+			{Seq: 1, Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), EventRequest: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 30}},
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithExporter(exporter))
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"kind=accounts", `{"id":"100","balance":30}` + "\n" + `{"id":"200","balance":0,"frozen":true}` + "\n"},
+		{"kind=accounts&format=csv", "id,balance,frozen\n100,30,false\n200,0,true\n"},
+		{"kind=events", `{"seq":1,"time":"2026-10-01T00:00:00Z","type":"deposit","destination":"100","amount":30}` + "\n"},
+		{"kind=events&format=csv", "seq,time,type,origin,destination,amount\n1,2026-10-01T00:00:00Z,deposit,,100,30\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/export?"+tt.query, nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("Expected 200 %q for %s, got %d %q", tt.want, tt.query, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestExport_Error(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithExporter(&MockExporter{Err: errors.New("storage failed")}))
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/export?kind=accounts", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when the export fails before any record, got %d", w.Code)
+	}
+}
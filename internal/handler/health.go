@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/health"
+)
+
+// WithHealthRegistry sets the registry backing /readyz. Serve marks it as
+// draining when shutdown starts.
+func WithHealthRegistry(registry *health.Registry) Option {
+	return func(h *HTTPHandler) {
+		h.health = registry
+	}
+}
+
+func (h *HTTPHandler) registerHealthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.handleHealthz)
+	mux.HandleFunc("GET /readyz", h.handleReadyz)
+	mux.HandleFunc("GET /version", h.handleVersion)
+}
+
+func (h *HTTPHandler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+}
+
+func (h *HTTPHandler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	result := h.health.Ready(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if result.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *HTTPHandler) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.buildInfo)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/health"
+)
+
+func TestHealthz(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{})
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestReadyz_NotReady(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("repository", func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithHealthRegistry(registry))
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	var result health.Result
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Checks["repository"] != "unreachable" {
+		t.Errorf("Expected repository failure in body, got %+v", result)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{})
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	var info health.BuildInfo
+	json.Unmarshal(w.Body.Bytes(), &info)
+	if w.Code != http.StatusOK || info.GoVersion == "" {
+		t.Errorf("Expected build info, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -1,51 +1,138 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/health"
+	"github.com/thihxm/ebanx-home-assignment/internal/metrics"
+	"github.com/thihxm/ebanx-home-assignment/internal/ratelimit"
+	"github.com/thihxm/ebanx-home-assignment/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var uni *ut.UniversalTranslator
 
 type HTTPHandler struct {
-	accountService domain.AccountService
-	eventService   domain.EventService
-	validate       *validator.Validate
+	accountService  domain.AccountService
+	eventService    domain.EventService
+	webhookService  domain.WebhookService
+	reviewService   domain.ReviewService
+	audit           domain.AuditLog
+	ledgerService   domain.LedgerService
+	importer        domain.Importer
+	exporter        domain.Exporter
+	snapshotService domain.SnapshotService
+	authenticator   auth.Authenticator
+	production      bool
+	clientLimiter   *ratelimit.Limiter
+	accountLimiter  *ratelimit.Limiter
+	validate        *validator.Validate
+	health          *health.Registry
+	metrics         *metrics.Metrics
+	tracerProvider  trace.TracerProvider
+	buildInfo       health.BuildInfo
+	serverConfig    ServerConfig
+	wsConfig        WebSocketConfig
+	hub             *wsHub
 }
 
-func NewAccountHTTPHandler(accountService domain.AccountService, eventService domain.EventService) *HTTPHandler {
+// Option customizes an HTTPHandler.
+type Option func(*HTTPHandler)
+
+// WithWebSocketConfig overrides the default WebSocket limits.
+func WithWebSocketConfig(cfg WebSocketConfig) Option {
+	return func(h *HTTPHandler) {
+		h.wsConfig = cfg
+	}
+}
+
+func NewAccountHTTPHandler(accountService domain.AccountService, eventService domain.EventService, opts ...Option) *HTTPHandler {
 	en := en.New()
 	uni = ut.New(en, en)
 	validate := validator.New()
 	enTrans, _ := uni.GetTranslator("en")
 	en_translations.RegisterDefaultTranslations(validate, enTrans)
 
-	return &HTTPHandler{
+	h := &HTTPHandler{
 		accountService: accountService,
 		eventService:   eventService,
 		validate:       validate,
+		health:         health.NewRegistry(),
+		buildInfo:      health.ReadBuildInfo(),
+		serverConfig:   DefaultServerConfig(),
+		wsConfig:       DefaultWebSocketConfig(),
+		hub:            newWSHub(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// OnEvent implements domain.EventListener, fanning account updates out to
+// WebSocket subscribers.
+func (h *HTTPHandler) OnEvent(ctx context.Context, event domain.EventRequest, resp *domain.EventResponse) {
+	if resp == nil {
+		return
+	}
+	if resp.Origin != nil {
+		h.hub.publish(*resp.Origin)
+	}
+	if resp.Destination != nil {
+		h.hub.publish(*resp.Destination)
 	}
 }
 
 func (h *HTTPHandler) registerRoutes(mux *http.ServeMux) error {
-	mux.HandleFunc("/reset", h.handleReset)
+	if !h.production {
+		mux.HandleFunc("/reset", h.requireRole(h.handleReset, domain.RoleAdmin))
+	}
 	mux.HandleFunc("/event", h.handleEvent)
 	mux.HandleFunc("/balance", h.handleGetBalance)
+	mux.HandleFunc("/ws", h.requireRole(h.handleWebSocket, domain.RoleOperator, domain.RoleAccountOwner))
+	h.registerAccountRoutes(mux)
+	h.registerHealthRoutes(mux)
+	if h.metrics != nil {
+		h.registerMetricsRoutes(mux)
+	}
+	if h.webhookService != nil {
+		h.registerWebhookRoutes(mux)
+	}
+	if h.reviewService != nil {
+		h.registerReviewRoutes(mux)
+	}
+	if h.audit != nil {
+		h.registerAuditRoutes(mux)
+	}
+	if h.ledgerService != nil {
+		h.registerLedgerRoutes(mux)
+	}
+	h.registerBulkRoutes(mux)
+	if h.snapshotService != nil {
+		h.registerSnapshotRoutes(mux)
+	}
 	return nil
 }
 
 func (h *HTTPHandler) handleReset(w http.ResponseWriter, r *http.Request) {
-	if err := h.accountService.Reset(); err != nil {
+	if err := h.accountService.Reset(r.Context()); err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error resetting state", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -56,31 +143,53 @@ func (h *HTTPHandler) handleReset(w http.ResponseWriter, r *http.Request) {
 func (h *HTTPHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
 	var req domain.EventRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeDecodeError(w, err)
 		return
 	}
 	err := h.validate.Struct(req)
 	if err != nil {
 		var invalidValidationError *validator.InvalidValidationError
 		if errors.As(err, &invalidValidationError) {
-			fmt.Println(err)
+			slog.ErrorContext(r.Context(), "Error validating event", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		var errs validator.ValidationErrors
-		var httpErrors []validator.ValidationErrorsTranslations
-		trans, _ := uni.GetTranslator(strings.Replace(r.Header.Get("Accept-Language"), "-", "_", -1))
-
-		if errors.As(err, &errs) {
-			httpErrors = append(httpErrors, errs.Translate(trans))
-		}
+		httpErrors := translateValidationErrors(err, r.Header.Get("Accept-Language"))
 		r, _ := json.Marshal(httpErrors)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(r)
 		return
 	}
-	resp, err := h.eventService.ProcessEvent(req)
+	err = h.authorize(r.Context(), func(p *domain.Principal) bool {
+		return p.CanSubmit(req)
+	})
+	if err != nil {
+		h.writeAuthError(w, err)
+		return
+	}
+	if d := h.allowAccount(req); !d.Allowed {
+		slog.InfoContext(r.Context(), "Account rate limited", "account_id", req.Origin)
+		writeRateLimitHeaders(w, d)
+		writeRateLimited(w, d)
+		return
+	}
+	resp, err := h.eventService.ProcessEvent(r.Context(), req)
 	if err != nil {
+		if writeContextError(w, r, err) || writeRiskError(w, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrAccountFrozen) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "0")
+			return
+		}
+		if errors.Is(err, domain.ErrAccountBlocked) {
+			// The list entry's reason is not disclosed to the caller.
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"code": "account_blocked"})
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(w, "0")
 		return
@@ -89,6 +198,17 @@ func (h *HTTPHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+func translateValidationErrors(err error, lang string) []validator.ValidationErrorsTranslations {
+	var errs validator.ValidationErrors
+	var httpErrors []validator.ValidationErrorsTranslations
+	trans, _ := uni.GetTranslator(strings.Replace(lang, "-", "_", -1))
+
+	if errors.As(err, &errs) {
+		httpErrors = append(httpErrors, errs.Translate(trans))
+	}
+	return httpErrors
+}
+
 func (h *HTTPHandler) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("account_id")
 	if id == "" {
@@ -96,8 +216,37 @@ func (h *HTTPHandler) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "missing account_id")
 		return
 	}
-	balance, err := h.accountService.GetBalance(id)
+	// as_of reads a past balance from the journal instead of the current one.
+	var asOf time.Time
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		var err error
+		if asOf, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "invalid as_of")
+			return
+		}
+		if h.ledgerService == nil {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+	}
+	err := h.authorize(r.Context(), func(p *domain.Principal) bool {
+		return p.CanRead(id)
+	})
 	if err != nil {
+		h.writeAuthError(w, err)
+		return
+	}
+	var balance int
+	if asOf.IsZero() {
+		balance, err = h.accountService.GetBalance(r.Context(), id)
+	} else {
+		balance, err = h.ledgerService.BalanceAsOf(r.Context(), id, asOf)
+	}
+	if err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(w, "0")
 		return
@@ -106,15 +255,24 @@ func (h *HTTPHandler) handleGetBalance(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "%d", balance)
 }
 
-func (h *HTTPHandler) Serve(addr string) error {
+func (h *HTTPHandler) routes() http.Handler {
 	mux := http.NewServeMux()
 	h.registerRoutes(mux)
-	server := &http.Server{
-		Addr:    addr,
-		Handler: mux,
+	// Authentication runs inside the body limit since signature checks
+	// read the body.
+	bodyLimit := func(r *http.Request) int64 {
+		if routedTo(mux, r, bulkRoutes) {
+			return h.serverConfig.MaxBulkBodyBytes
+		}
+		return h.serverConfig.MaxBodyBytes
 	}
-
-	log.Printf("Server started on %s", addr)
-
-	return server.ListenAndServe()
+	handler := limitBody(h.authenticate(h.limitClients(mux)), bodyLimit)
+	if h.metrics != nil {
+		handler = h.metrics.Middleware(handler)
+	}
+	if h.tracerProvider != nil {
+		handler = tracing.Middleware(h.tracerProvider, handler)
+	}
+	streaming := func(r *http.Request) bool { return routedTo(mux, r, streamingRoutes) }
+	return requestLogger(withDeadline(handler, h.serverConfig.RequestTimeout, streaming))
 }
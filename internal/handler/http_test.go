@@ -2,13 +2,17 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
 )
 
 type MockService struct {
@@ -18,35 +22,40 @@ type MockService struct {
 	TransferFunc     func(string, string, int) (*domain.Account, *domain.Account, error)
 	ProcessEventFunc func(domain.EventRequest) (*domain.EventResponse, error)
 	ResetFunc        func() error
+	SetFrozenFunc    func(string, bool) (*domain.Account, error)
 }
 
-func (m *MockService) GetBalance(id string) (int, error) {
+func (m *MockService) GetBalance(ctx context.Context, id string) (int, error) {
 	return m.BalanceFunc(id)
 }
 
-func (m *MockService) Deposit(id string, amount int) (*domain.Account, error) {
+func (m *MockService) Deposit(ctx context.Context, id string, amount int) (*domain.Account, error) {
 	return m.DepositFunc(id, amount)
 }
 
-func (m *MockService) Withdraw(id string, amount int) (*domain.Account, error) {
+func (m *MockService) Withdraw(ctx context.Context, id string, amount int) (*domain.Account, error) {
 	return m.WithdrawFunc(id, amount)
 }
 
-func (m *MockService) Transfer(originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
+func (m *MockService) Transfer(ctx context.Context, originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
 	return m.TransferFunc(originID, destinationID, amount)
 }
 
-func (m *MockService) ProcessEvent(req domain.EventRequest) (*domain.EventResponse, error) {
+func (m *MockService) ProcessEvent(ctx context.Context, req domain.EventRequest) (*domain.EventResponse, error) {
 	return m.ProcessEventFunc(req)
 }
 
-func (m *MockService) Reset() error {
+func (m *MockService) Reset(ctx context.Context) error {
 	if m.ResetFunc != nil {
 		return m.ResetFunc()
 	}
 	return nil
 }
 
+func (m *MockService) SetFrozen(ctx context.Context, id string, frozen bool) (*domain.Account, error) {
+	return m.SetFrozenFunc(id, frozen)
+}
+
 func TestGetBalance_Success(t *testing.T) {
 	mockSvc := &MockService{
 		BalanceFunc: func(id string) (int, error) {
@@ -122,3 +131,121 @@ func TestHandleEvent_Deposit(t *testing.T) {
 		t.Errorf("Expected balance 10, got %d", actualResp.Destination.Balance)
 	}
 }
+
+func TestHandleEvent_AccountBlocked(t *testing.T) {
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return nil, &domain.ScreeningError{AccountID: "666", Reason: "OFAC SDN"}
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc)
+
+	body := []byte(`{"type":"transfer", "origin":"100", "destination":"666", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.handleEvent(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+	if got := w.Body.String(); got != "{\"code\":\"account_blocked\"}\n" {
+		t.Errorf("Expected only the account_blocked code, got %q", got)
+	}
+}
+
+func TestHandleEvent_SelfTransfer(t *testing.T) {
+	ctx := context.Background()
+	accounts := service.NewAccountService(repository.NewInMemoryRepository())
+	accounts.Deposit(ctx, "100", 50)
+	h := NewAccountHTTPHandler(accounts, service.NewEventService(accounts))
+
+	body := `{"type":"transfer", "origin":"100", "amount":15, "destination":"100"}`
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body)))
+
+	var resp domain.EventResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 with a JSON body, got %d %q", w.Code, w.Body.String())
+	}
+	if resp.Origin == nil || resp.Origin.Balance != 50 || resp.Destination == nil || resp.Destination.Balance != 50 {
+		t.Errorf("Expected both sides to report the stored balance 50, got origin %+v destination %+v", resp.Origin, resp.Destination)
+	}
+}
+
+// FuzzHandleEvent sends arbitrary bodies to POST /event over real services.
+// Whatever the body, the handler must answer with a known status, and only
+// a 201 may move money, by exactly the amount the event names.
+func FuzzHandleEvent(f *testing.F) {
+	for _, seed := range []string{
+		`{"type":"deposit", "destination":"100", "amount":10}`,
+		`{"type":"withdraw", "origin":"100", "amount":5}`,
+		`{"type":"transfer", "origin":"100", "amount":15, "destination":"300"}`,
+		`{"type":"transfer", "origin":"100", "amount":15, "destination":"100"}`,
+		`{"type":"withdraw", "origin":"100", "amount":51}`,
+		`{"type":"deposit", "destination":"100", "amount":-10}`,
+		`{"type":"deposit", "destination":"abc", "amount":10}`,
+		`{"type":"deposit", "destination":"100", "amount":1e30}`,
+		`{"type":"deposit", "destination":"100", "amount":"10"}`,
+		`{"type":"deposit", "destination":"100", "amount":10}{"type":"withdraw"}`,
+		`{"type":"loan", "destination":"100", "amount":10}`,
+		`[]`,
+		`null`,
+		``,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		ctx := context.Background()
+		repo := repository.NewInMemoryRepository()
+		accounts := service.NewAccountService(repo)
+		accounts.Deposit(ctx, "100", 50)
+		h := NewAccountHTTPHandler(accounts, service.NewEventService(accounts))
+
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body)))
+
+		// The handler reads the first JSON value, so the expected effect is
+		// worked out the same way.
+		var event domain.EventRequest
+		json.NewDecoder(strings.NewReader(body)).Decode(&event)
+		want := 50
+		switch w.Code {
+		case http.StatusCreated:
+			var resp domain.EventResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Expected a JSON event response, got %q: %v", w.Body.String(), err)
+			}
+			for _, a := range []*domain.Account{resp.Origin, resp.Destination} {
+				if a == nil {
+					continue
+				}
+				if got, _ := accounts.GetBalance(ctx, a.ID); got != a.Balance {
+					t.Errorf("Expected the response to match account %s at %d, got %d", a.ID, got, a.Balance)
+				}
+			}
+			switch event.Type {
+			case "deposit":
+				want += event.Amount
+			case "withdraw":
+				want -= event.Amount
+			}
+		case http.StatusBadRequest, http.StatusNotFound:
+		default:
+			t.Fatalf("Expected 201, 400 or 404 for %q, got %d", body, w.Code)
+		}
+
+		stored, _, _ := repo.ListAccounts(ctx)
+		total := 0
+		for _, a := range stored {
+			if a.Balance < 0 {
+				t.Errorf("Expected no negative balances, got %+v", a)
+			}
+			total += a.Balance
+		}
+		if total != want {
+			t.Errorf("Expected %d in all accounts after %d for %q, got %d", want, w.Code, body, total)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithLedgerService enables the ledger endpoints.
+func WithLedgerService(ledgerService domain.LedgerService) Option {
+	return func(h *HTTPHandler) {
+		h.ledgerService = ledgerService
+	}
+}
+
+func (h *HTTPHandler) registerLedgerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /ledger/trial-balance", h.requireRole(h.handleTrialBalance, domain.RoleOperator))
+	mux.HandleFunc("GET /accounts/{id}/statement", h.handleStatement)
+}
+
+// handleTrialBalance answers 500 when the books do not balance, so it can be
+// used as a monitoring check.
+func (h *HTTPHandler) handleTrialBalance(w http.ResponseWriter, r *http.Request) {
+	tb, err := h.ledgerService.TrialBalance(r.Context())
+	if err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error computing trial balance", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if tb.OK() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(tb)
+}
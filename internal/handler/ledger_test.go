@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type MockLedgerService struct {
+	TrialBalanceFunc func() (*domain.TrialBalance, error)
+	BalanceAsOfFunc  func(string, time.Time) (int, error)
+	StatementFunc    func(string, time.Time, time.Time, domain.StatementWriter) error
+}
+
+func (m *MockLedgerService) TrialBalance(ctx context.Context) (*domain.TrialBalance, error) {
+	return m.TrialBalanceFunc()
+}
+
+func (m *MockLedgerService) BalanceAsOf(ctx context.Context, id string, at time.Time) (int, error) {
+	return m.BalanceAsOfFunc(id, at)
+}
+
+func (m *MockLedgerService) Statement(ctx context.Context, id string, from, to time.Time, w domain.StatementWriter) error {
+	return m.StatementFunc(id, from, to, w)
+}
+
+func TestTrialBalance_Status(t *testing.T) {
+	tests := []struct {
+		name string
+		tb   domain.TrialBalance
+		want int
+	}{
+		{"balanced", domain.TrialBalance{Balanced: true}, http.StatusOK},
+		{"unbalanced", domain.TrialBalance{}, http.StatusInternalServerError},
+		{"mismatch", domain.TrialBalance{Balanced: true, Mismatches: []domain.BalanceMismatch{{Account: "100"}}}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		ledger := &MockLedgerService{
+			TrialBalanceFunc: func() (*domain.TrialBalance, error) { return &tt.tb, nil },
+		}
+		h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithLedgerService(ledger))
+
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ledger/trial-balance", nil))
+
+		if w.Code != tt.want {
+			t.Errorf("Expected status %d for %s, got %d", tt.want, tt.name, w.Code)
+		}
+	}
+}
+
+func TestGetBalance_AsOf(t *testing.T) {
+	ledger := &MockLedgerService{
+		BalanceAsOfFunc: func(id string, at time.Time) (int, error) {
+			if at.Before(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+				return 0, domain.ErrAccountNotFound
+			}
+			return 42, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithLedgerService(ledger))
+
+	tests := []struct {
+		asOf     string
+		wantCode int
+		wantBody string
+	}{
+		{"2026-10-01T00:00:00Z", http.StatusOK, "42"},
+		{"2026-10-01T03:00:00%2B03:00", http.StatusOK, "42"},
+		{"2026-09-01T00:00:00Z", http.StatusNotFound, "0"},
+		{"yesterday", http.StatusBadRequest, "invalid as_of"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/balance?account_id=100&as_of="+tt.asOf, nil))
+
+		if w.Code != tt.wantCode || w.Body.String() != tt.wantBody {
+			t.Errorf("Expected %d %q for %s, got %d %q", tt.wantCode, tt.wantBody, tt.asOf, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGetBalance_AsOfWithoutLedger(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{})
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/balance?account_id=100&as_of=2026-10-01T00:00:00Z", nil))
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", w.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/logging"
+	"github.com/thihxm/ebanx-home-assignment/internal/respwriter"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// requestLogger assigns every request an ID, honouring a well-formed
+// incoming X-Request-ID, and writes an access log line once it completes.
+// The ID is echoed in the response and carried in the request context.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		rec := respwriter.Wrap(w)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(rec, r)
+
+		slog.InfoContext(ctx, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", r.Pattern,
+			"status", rec.Status(),
+			"bytes", rec.Bytes(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
+
+// validRequestID accepts short IDs of printable ASCII so client-supplied
+// values can't inject control characters into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/logging"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRequestID_Honoured(t *testing.T) {
+	var seen string
+	mockSvc := &MockService{
+		BalanceFunc: func(id string) (int, error) {
+			return 10, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&contextSpy{MockService: mockSvc, seen: &seen}, mockSvc)
+
+	req := httptest.NewRequest(http.MethodGet, "/balance?account_id=100", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("Expected echoed request ID, got %q", w.Header().Get(RequestIDHeader))
+	}
+	if seen != "abc-123" {
+		t.Errorf("Expected request ID in service context, got %q", seen)
+	}
+}
+
+func TestRequestID_GeneratedWhenInvalid(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	id := w.Header().Get(RequestIDHeader)
+	if id == "" || id == "bad id\n" {
+		t.Errorf("Expected generated request ID, got %q", id)
+	}
+}
+
+// contextSpy records the request ID seen by the service layer.
+type contextSpy struct {
+	*MockService
+	seen *string
+}
+
+func (s *contextSpy) GetBalance(ctx context.Context, id string) (int, error) {
+	*s.seen = logging.RequestID(ctx)
+	return s.MockService.GetBalance(ctx, id)
+}
+
+func TestRequestLogger_RouteWithTracing(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logging.New(&logs, logging.Options{}))
+
+	mockSvc := &MockService{
+		BalanceFunc: func(id string) (int, error) {
+			return 10, nil
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc, WithTracing(sdktrace.NewTracerProvider()))
+
+	h.routes().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/balance?account_id=100", nil))
+
+	if !strings.Contains(logs.String(), `"route":"/balance"`) {
+		t.Errorf("Expected the route in the access log, got %s", logs.String())
+	}
+}
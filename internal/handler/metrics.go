@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/metrics"
+)
+
+// WithMetrics instruments every route and exposes GET /metrics.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(h *HTTPHandler) {
+		h.metrics = m
+	}
+}
+
+func (h *HTTPHandler) registerMetricsRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /metrics", h.metrics.Handler())
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/metrics"
+)
+
+func TestMetrics_RecordsRoutesAndWebSocketUpgrade(t *testing.T) {
+	server, _ := newWSTestServer(t, WithMetrics(metrics.New()))
+
+	// The instrumented writer must still support hijacking.
+	conn := dialWS(t, server)
+	conn.WriteJSON(wsRequest{
+		ID:     "1",
+		Action: wsActionEvent,
+		Event:  &domain.EventRequest{Type: "deposit", Destination: "100", Amount: 10},
+	})
+	readWS(t, conn)
+
+	http.Get(server.URL + "/balance?account_id=100")
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Expected no error scraping: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.Contains(string(body), `ipkiss_http_requests_total{method="GET",route="/balance",status="200"} 1`) {
+		t.Errorf("Expected /balance request count in metrics output")
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/ratelimit"
+)
+
+// WithClientRateLimit limits requests per client: the authenticated
+// principal, or the remote IP for anonymous requests. Failed authentication
+// attempts also count against the remote IP, which is refused outright once
+// they use up its bucket. Probe and metrics routes are exempt.
+func WithClientRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(h *HTTPHandler) {
+		h.clientLimiter = limiter
+	}
+}
+
+// WithAccountRateLimit limits withdrawals and transfers per origin account,
+// however many clients they come from.
+func WithAccountRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(h *HTTPHandler) {
+		h.accountLimiter = limiter
+	}
+}
+
+var rateLimitExempt = []string{"/healthz", "/readyz", "/version", "/metrics"}
+
+func (h *HTTPHandler) limitClients(next http.Handler) http.Handler {
+	if !h.clientLimiter.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(rateLimitExempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := clientKey(r)
+		d := h.clientLimiter.Allow(key)
+		writeRateLimitHeaders(w, d)
+		if !d.Allowed {
+			slog.InfoContext(r.Context(), "Client rate limited", "client", key)
+			writeRateLimited(w, d)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowAccount applies the per-account limit to events that debit an
+// account.
+func (h *HTTPHandler) allowAccount(event domain.EventRequest) ratelimit.Decision {
+	if event.Type != "withdraw" && event.Type != "transfer" {
+		return ratelimit.Decision{Allowed: true}
+	}
+	return h.accountLimiter.Allow(event.Origin)
+}
+
+func clientKey(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return "principal:" + p.ID
+	}
+	return remoteKey(r)
+}
+
+// remoteKey identifies the caller by IP, which anonymous requests and
+// failed authentication attempts are limited by.
+func remoteKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func writeRateLimitHeaders(w http.ResponseWriter, d ratelimit.Decision) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+}
+
+func writeRateLimited(w http.ResponseWriter, d ratelimit.Decision) {
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+	w.WriteHeader(http.StatusTooManyRequests)
+	fmt.Fprint(w, "too many requests")
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/ratelimit"
+)
+
+func newRateLimitTestHandler(opts ...Option) *HTTPHandler {
+	mockSvc := &MockService{
+		BalanceFunc: func(id string) (int, error) {
+			return 10, nil
+		},
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return &domain.EventResponse{}, nil
+		},
+	}
+	return NewAccountHTTPHandler(mockSvc, mockSvc, opts...)
+}
+
+func postEvent(h http.Handler, body, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestClientRateLimit(t *testing.T) {
+	h := newRateLimitTestHandler(WithClientRateLimit(ratelimit.NewLimiter(ratelimit.Config{Rate: 1, Burst: 2}))).routes()
+	body := `{"type":"deposit", "destination":"100", "amount":10}`
+
+	w := postEvent(h, body, "10.0.0.1:1234")
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("Expected RateLimit headers, got %v", w.Header())
+	}
+	postEvent(h, body, "10.0.0.1:1234")
+
+	w = postEvent(h, body, "10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := postEvent(h, body, "10.0.0.2:1234"); w.Code != http.StatusCreated {
+		t.Errorf("Expected other clients to be unaffected, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected probes to be exempt, got %d", rec.Code)
+	}
+}
+
+func TestAccountRateLimit(t *testing.T) {
+	h := newRateLimitTestHandler(WithAccountRateLimit(ratelimit.NewLimiter(ratelimit.Config{Rate: 1, Burst: 1}))).routes()
+	withdraw := `{"type":"withdraw", "origin":"100", "amount":1}`
+	transfer := `{"type":"transfer", "origin":"100", "destination":"200", "amount":1}`
+
+	if w := postEvent(h, withdraw, "10.0.0.1:1"); w.Code != http.StatusCreated {
+		t.Fatalf("Expected first withdrawal to pass, got %d", w.Code)
+	}
+	// A different client debiting the same account shares its bucket.
+	if w := postEvent(h, transfer, "10.0.0.2:1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected transfer from the same account to be limited, got %d", w.Code)
+	}
+	if w := postEvent(h, `{"type":"deposit", "destination":"100", "amount":1}`, "10.0.0.1:1"); w.Code != http.StatusCreated {
+		t.Errorf("Expected deposits not to be account limited, got %d", w.Code)
+	}
+}
+
+func TestClientRateLimit_FailedAuthentication(t *testing.T) {
+	h := newAuthTestHandler(t, WithClientRateLimit(ratelimit.NewLimiter(ratelimit.Config{Rate: 1, Burst: 3}))).routes()
+	get := func(key, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/balance?account_id=100", nil)
+		req.Header.Set(auth.APIKeyHeader, key)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := get("guess", "10.0.0.1:1234"); code != http.StatusUnauthorized {
+			t.Fatalf("Expected guess %d to get 401, got %d", i, code)
+		}
+	}
+	if code := get("guess", "10.0.0.1:1234"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected repeated failures to get 429, got %d", code)
+	}
+	if code := get("operator-key", "10.0.0.1:1234"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the IP to be refused before its credentials are checked, got %d", code)
+	}
+	if code := get("operator-key", "10.0.0.2:1234"); code != http.StatusOK {
+		t.Errorf("Expected other IPs to be unaffected, got %d", code)
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/auth"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithReviewService enables the endpoints for deciding on events held by the
+// risk rules.
+func WithReviewService(reviewService domain.ReviewService) Option {
+	return func(h *HTTPHandler) {
+		h.reviewService = reviewService
+	}
+}
+
+func (h *HTTPHandler) registerReviewRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /reviews", h.requireRole(h.handleListReviews, domain.RoleAdmin))
+	mux.HandleFunc("POST /reviews/{id}/approve", h.requireRole(h.handleApproveReview, domain.RoleAdmin))
+	mux.HandleFunc("POST /reviews/{id}/reject", h.requireRole(h.handleRejectReview, domain.RoleAdmin))
+}
+
+func (h *HTTPHandler) handleListReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.reviewService.Reviews(r.Context(), domain.ReviewStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reviews)
+}
+
+func (h *HTTPHandler) handleApproveReview(w http.ResponseWriter, r *http.Request) {
+	review, err := h.reviewService.Approve(r.Context(), r.PathValue("id"), reviewer(r))
+	if err != nil {
+		writeReviewError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+func (h *HTTPHandler) handleRejectReview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+	}
+	review, err := h.reviewService.Reject(r.Context(), r.PathValue("id"), reviewer(r), req.Note)
+	if err != nil {
+		writeReviewError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(review)
+}
+
+// reviewer names the caller deciding on a review, or "" when authentication
+// is disabled.
+func reviewer(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return p.ID
+	}
+	return ""
+}
+
+func writeReviewError(w http.ResponseWriter, r *http.Request, err error) {
+	if writeContextError(w, r, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrReviewNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, err.Error())
+	case errors.Is(err, domain.ErrReviewDecided):
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "Error deciding review", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeRiskError answers an event stopped by the risk rules: 202 with the
+// review when it was held, 403 with the rule when it was denied. It reports
+// false, writing nothing, for any other error.
+func writeRiskError(w http.ResponseWriter, err error) bool {
+	var riskErr *domain.RiskError
+	if !errors.As(err, &riskErr) {
+		return false
+	}
+	if riskErr.Review != nil {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(riskErr.Review)
+		return true
+	}
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"code":   "risk_denied",
+		"rule":   riskErr.Rule,
+		"reason": riskErr.Reason,
+	})
+	return true
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type MockReviewService struct {
+	ApproveFunc func(string, string) (*domain.Review, error)
+	RejectFunc  func(string, string, string) (*domain.Review, error)
+}
+
+func (m *MockReviewService) Reviews(ctx context.Context, status domain.ReviewStatus) ([]domain.Review, error) {
+	return nil, nil
+}
+
+func (m *MockReviewService) Approve(ctx context.Context, id, reviewer string) (*domain.Review, error) {
+	return m.ApproveFunc(id, reviewer)
+}
+
+func (m *MockReviewService) Reject(ctx context.Context, id, reviewer, note string) (*domain.Review, error) {
+	return m.RejectFunc(id, reviewer, note)
+}
+
+func TestHandleEvent_PendingReview(t *testing.T) {
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return nil, &domain.RiskError{Rule: "velocity", Reason: "too fast", Review: &domain.Review{ID: "rev_1", Status: domain.ReviewPending}}
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc)
+
+	body := []byte(`{"type":"withdraw", "origin":"100", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", w.Code)
+	}
+	var review domain.Review
+	json.Unmarshal(w.Body.Bytes(), &review)
+	if review.ID != "rev_1" {
+		t.Errorf("Expected review rev_1, got %q", review.ID)
+	}
+}
+
+func TestHandleEvent_RiskDenied(t *testing.T) {
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			return nil, &domain.RiskError{Rule: "blocklist", Reason: "account 100 is blocklisted"}
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc)
+
+	body := []byte(`{"type":"withdraw", "origin":"100", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+	var resp map[string]string
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["code"] != "risk_denied" || resp["rule"] != "blocklist" {
+		t.Errorf("Expected risk_denied by blocklist, got %v", resp)
+	}
+}
+
+func TestApproveReview(t *testing.T) {
+	mockReviews := &MockReviewService{
+		ApproveFunc: func(id, reviewer string) (*domain.Review, error) {
+			switch id {
+			case "rev_1":
+				return &domain.Review{ID: id, Status: domain.ReviewApproved}, nil
+			case "rev_2":
+				return nil, domain.ErrReviewDecided
+			default:
+				return nil, domain.ErrReviewNotFound
+			}
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithReviewService(mockReviews))
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"rev_1", http.StatusOK},
+		{"rev_2", http.StatusConflict},
+		{"rev_3", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/reviews/"+tt.id+"/approve", nil)
+		w := httptest.NewRecorder()
+
+		h.routes().ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("Expected status %d for %s, got %d", tt.want, tt.id, w.Code)
+		}
+	}
+}
+
+func TestRejectReview_Note(t *testing.T) {
+	var gotNote string
+	mockReviews := &MockReviewService{
+		RejectFunc: func(id, reviewer, note string) (*domain.Review, error) {
+			gotNote = note
+			return &domain.Review{ID: id, Status: domain.ReviewRejected, Note: note}, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithReviewService(mockReviews))
+
+	body := []byte(`{"note":"known fraud ring"}`)
+	req := httptest.NewRequest(http.MethodPost, "/reviews/rev_1/reject", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if gotNote != "known fraud ring" {
+		t.Errorf("Expected note to be passed through, got %q", gotNote)
+	}
+}
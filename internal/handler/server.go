@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// once shutdown starts.
+	ShutdownTimeout time.Duration
+	MaxHeaderBytes  int
+	// MaxBodyBytes caps request bodies; larger requests get 413.
+	MaxBodyBytes int64
+	// MaxBulkBodyBytes caps request bodies on bulkRoutes instead of
+	// MaxBodyBytes.
+	MaxBulkBodyBytes int64
+	// RequestTimeout bounds the handling of a single request, or of a single
+	// WebSocket frame. Requests past it get 503. Zero disables it.
+	RequestTimeout time.Duration
+}
+
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		MaxBodyBytes:      1 << 20,
+		MaxBulkBodyBytes:  64 << 20,
+		RequestTimeout:    5 * time.Second,
+	}
+}
+
+// WithServerConfig overrides the default server timeouts and size limits.
+func WithServerConfig(cfg ServerConfig) Option {
+	return func(h *HTTPHandler) {
+		h.serverConfig = cfg
+	}
+}
+
+// Handler returns the routes wrapped in the same middleware Serve uses, for
+// serving them in-process.
+func (h *HTTPHandler) Handler() http.Handler {
+	return h.routes()
+}
+
+// Serve listens on addr until ctx is cancelled, then stops accepting
+// connections and waits up to ShutdownTimeout for in-flight requests to
+// finish. Readiness fails from then on, and WebSocket clients are sent a
+// going-away close frame.
+func (h *HTTPHandler) Serve(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           h.routes(),
+		ReadHeaderTimeout: h.serverConfig.ReadHeaderTimeout,
+		ReadTimeout:       h.serverConfig.ReadTimeout,
+		WriteTimeout:      h.serverConfig.WriteTimeout,
+		IdleTimeout:       h.serverConfig.IdleTimeout,
+		MaxHeaderBytes:    h.serverConfig.MaxHeaderBytes,
+	}
+	server.RegisterOnShutdown(h.hub.closeAll)
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("Server started", "addr", addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	h.health.SetDraining(true)
+	slog.Info("Shutting down server", "drain_timeout", h.serverConfig.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), h.serverConfig.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// bulkRoutes take files rather than single records, so their bodies are
+// capped by MaxBulkBodyBytes.
+var bulkRoutes = map[string]bool{
+	"POST /admin/import":  true,
+	"POST /admin/restore": true,
+}
+
+// limitBody caps each request body at maxBytes(r). Zero leaves it
+// uncapped.
+func limitBody(next http.Handler, maxBytes func(*http.Request) int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := maxBytes(r); n > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// streamingRoutes are exempt from RequestTimeout, since their responses grow
+// with the data. Each flushed chunk extends the write deadline instead, so a
+// stalled client still times out.
+var streamingRoutes = map[string]bool{
+	"GET /accounts/{id}/statement": true,
+	"GET /admin/export":            true,
+}
+
+// routedTo reports whether mux routes r to one of routes.
+func routedTo(mux *http.ServeMux, r *http.Request, routes map[string]bool) bool {
+	_, pattern := mux.Handler(r)
+	return routes[pattern]
+}
+
+// withDeadline cancels the request context after timeout. WebSocket
+// upgrades are exempt, since the connection outlives any single request;
+// each frame gets its own deadline instead. So is any request exempt
+// reports true for.
+func withDeadline(next http.Handler, timeout time.Duration, exempt func(*http.Request) bool) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) || (exempt != nil && exempt(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		serveWithContext(next, w, r, ctx)
+	})
+}
+
+// serveWithContext runs next with ctx and copies the matched route pattern
+// back to r, so outer middleware can still label the request by route.
+func serveWithContext(next http.Handler, w http.ResponseWriter, r *http.Request, ctx context.Context) {
+	inner := r.WithContext(ctx)
+	next.ServeHTTP(w, inner)
+	r.Pattern = inner.Pattern
+}
+
+// isContextError reports whether err comes from a done context, either a
+// deadline or a client that went away.
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// writeContextError answers 503 when err comes from the request context. It
+// reports false, writing nothing, for any other error.
+func writeContextError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if !isContextError(err) {
+		return false
+	}
+	slog.WarnContext(r.Context(), "Request abandoned", "error", err)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	return true
+}
+
+// streamFlushLines is how many records a stream writes between flushes.
+const streamFlushLines = 100
+
+// streamResponse writes a 200 response record by record. The status goes out
+// with the first record, and a failure after that can only abort the
+// connection, so the client sees a truncated body.
+type streamResponse struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	contentType  string
+	writeTimeout time.Duration
+	started      bool
+	records      int
+}
+
+// newStreamResponse streams to w. A non-zero writeTimeout is applied afresh
+// when the stream starts and after every flush, so a long stream outlives
+// the server's WriteTimeout as long as the client keeps reading.
+func newStreamResponse(w http.ResponseWriter, contentType string, writeTimeout time.Duration) *streamResponse {
+	return &streamResponse{w: w, rc: http.NewResponseController(w), contentType: contentType, writeTimeout: writeTimeout}
+}
+
+func (s *streamResponse) start() {
+	if s.started {
+		return
+	}
+	s.extendWriteDeadline()
+	s.w.Header().Set("Content-Type", s.contentType)
+	s.w.WriteHeader(http.StatusOK)
+	s.started = true
+}
+
+// written counts a record and, every streamFlushLines records, flushes
+// buffered output and then the connection.
+func (s *streamResponse) written(flush func() error) error {
+	if s.records++; s.records%streamFlushLines != 0 {
+		return nil
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return s.extendWriteDeadline()
+}
+
+func (s *streamResponse) extendWriteDeadline() error {
+	if s.writeTimeout <= 0 {
+		return nil
+	}
+	err := s.rc.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// abort ends a stream that failed after it started.
+func (s *streamResponse) abort(r *http.Request, msg string, args ...any) {
+	slog.ErrorContext(r.Context(), msg, args...)
+	panic(http.ErrAbortHandler)
+}
+
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+}
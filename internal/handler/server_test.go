@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestHandleEvent_BodyTooLarge(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.MaxBodyBytes = 16
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithServerConfig(cfg))
+
+	body := []byte(`{"type":"deposit", "destination":"100", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockSvc := &MockService{
+		ProcessEventFunc: func(req domain.EventRequest) (*domain.EventResponse, error) {
+			close(started)
+			<-release
+			return &domain.EventResponse{Destination: &domain.Account{ID: "100", Balance: 10}}, nil
+		},
+	}
+	h := NewAccountHTTPHandler(mockSvc, mockSvc)
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- h.Serve(ctx, addr)
+	}()
+
+	var resp *http.Response
+	var err error
+	responded := make(chan struct{})
+	go func() {
+		defer close(responded)
+		for i := 0; i < 50; i++ {
+			body := bytes.NewBufferString(`{"type":"deposit", "destination":"100", "amount":10}`)
+			resp, err = http.Post("http://"+addr+"/event", "application/json", body)
+			if err == nil || !isConnRefused(err) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-served:
+		t.Fatalf("Expected Serve to wait for the in-flight request")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-responded
+	if err != nil {
+		t.Fatalf("Expected in-flight request to complete: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.StatusCode)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+}
+
+func isConnRefused(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// blockingService waits for the request context to end, like a slow
+// repository would.
+type blockingService struct {
+	MockService
+}
+
+func (s *blockingService) ProcessEvent(ctx context.Context, req domain.EventRequest) (*domain.EventResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHandleEvent_RequestTimeout(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.RequestTimeout = 20 * time.Millisecond
+	svc := &blockingService{}
+	h := NewAccountHTTPHandler(svc, svc, WithServerConfig(cfg))
+
+	body := []byte(`{"type":"deposit", "destination":"100", "amount":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/event", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestWithDeadline_KeepsRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /balance", func(w http.ResponseWriter, r *http.Request) {})
+	handler := withDeadline(mux, time.Second, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if req.Pattern != "GET /balance" {
+		t.Errorf("Expected route pattern to reach outer middleware, got %q", req.Pattern)
+	}
+}
+
+func TestWithDeadline_ExemptsStreamingRoutes(t *testing.T) {
+	deadlines := make(map[string]bool)
+	mux := http.NewServeMux()
+	record := func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		deadlines[r.Pattern] = ok
+	}
+	mux.HandleFunc("GET /accounts/{id}/statement", record)
+	mux.HandleFunc("GET /admin/export", record)
+	mux.HandleFunc("GET /balance", record)
+	handler := withDeadline(mux, time.Second, func(r *http.Request) bool { return routedTo(mux, r, streamingRoutes) })
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/100/statement", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/balance", nil))
+
+	if deadlines["GET /accounts/{id}/statement"] {
+		t.Errorf("Expected no deadline on the statement")
+	}
+	if deadlines["GET /admin/export"] {
+		t.Errorf("Expected no deadline on the export")
+	}
+	if !deadlines["GET /balance"] {
+		t.Errorf("Expected a deadline on the balance")
+	}
+}
+
+func TestStreamResponse_OutlivesWriteTimeout(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	const records = 10 * streamFlushLines
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream := newStreamResponse(w, "text/plain", timeout)
+		stream.start()
+		for i := 0; i < records; i++ {
+			fmt.Fprintln(w, i)
+			// Each flushed chunk takes a fraction of the timeout, but the
+			// whole stream takes longer than it.
+			time.Sleep(timeout / streamFlushLines / 4)
+			if err := stream.written(func() error { return nil }); err != nil {
+				t.Errorf("Expected no error writing, got %v", err)
+				return
+			}
+		}
+		time.Sleep(timeout / 2)
+		fmt.Fprintln(w, "end")
+	}))
+	server.Config.WriteTimeout = timeout
+	server.Start()
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !strings.HasSuffix(string(body), "end\n") {
+		t.Errorf("Expected the whole stream, got %d bytes (err %v)", len(body), err)
+	}
+}
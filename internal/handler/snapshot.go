@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithSnapshotService enables the snapshot and restore endpoints.
+func WithSnapshotService(snapshotService domain.SnapshotService) Option {
+	return func(h *HTTPHandler) {
+		h.snapshotService = snapshotService
+	}
+}
+
+func (h *HTTPHandler) registerSnapshotRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/snapshot", h.requireRole(h.handleSnapshot, domain.RoleAdmin))
+	mux.HandleFunc("POST /admin/restore", h.requireRole(h.handleRestore, domain.RoleAdmin))
+}
+
+func (h *HTTPHandler) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.snapshotService.Snapshot(r.Context())
+	if err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error taking snapshot", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="snapshot-%d.json"`, snapshot.JournalSeq))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+func (h *HTTPHandler) handleRestore(w http.ResponseWriter, r *http.Request) {
+	var snapshot domain.Snapshot
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&snapshot); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := h.snapshotService.Restore(r.Context(), &snapshot); err != nil {
+		if writeContextError(w, r, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrSnapshotVersion) || errors.Is(err, domain.ErrSnapshotChecksum) || errors.Is(err, domain.ErrInvalidSnapshot) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error restoring snapshot", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+}
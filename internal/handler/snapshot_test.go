@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type MockSnapshotService struct {
+	SnapshotFunc func() (*domain.Snapshot, error)
+	RestoreFunc  func(*domain.Snapshot) error
+}
+
+func (m *MockSnapshotService) Snapshot(ctx context.Context) (*domain.Snapshot, error) {
+	return m.SnapshotFunc()
+}
+
+func (m *MockSnapshotService) Restore(ctx context.Context, s *domain.Snapshot) error {
+	return m.RestoreFunc(s)
+}
+
+func TestSnapshot(t *testing.T) {
+	snapshots := &MockSnapshotService{
+		SnapshotFunc: func() (*domain.Snapshot, error) {
+			s := &domain.Snapshot{Version: domain.SnapshotVersion, JournalSeq: 12, Accounts: []domain.Account{{ID: "100", Balance: 5}}}
+			s.Checksum = s.Sum()
+			return s, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithSnapshotService(snapshots))
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "snapshot-12.json") {
+		t.Fatalf("Expected 200 with a snapshot attachment, got %d %q", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	var got domain.Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Verify() != nil {
+		t.Errorf("Expected a verifiable snapshot, got %s (err %v)", w.Body.String(), err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	var restored *domain.Snapshot
+	snapshots := &MockSnapshotService{
+		RestoreFunc: func(s *domain.Snapshot) error {
+			if err := s.Verify(); err != nil {
+				return err
+			}
+			restored = s
+			return nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithSnapshotService(snapshots))
+
+	valid := domain.Snapshot{Version: domain.SnapshotVersion, Accounts: []domain.Account{{ID: "100", Balance: 5}}}
+	valid.Checksum = valid.Sum()
+	body, _ := json.Marshal(valid)
+	tampered := strings.Replace(string(body), `"balance":5`, `"balance":500`, 1)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"valid", string(body), http.StatusOK},
+		{"tampered", tampered, http.StatusUnprocessableEntity},
+		{"unknown field", `{"version":1,"accounts":[],"owner":"x"}`, http.StatusBadRequest},
+		{"not json", `snapshot`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(tt.body)))
+
+		if w.Code != tt.wantCode {
+			t.Errorf("Expected status %d for a %s snapshot, got %d: %s", tt.wantCode, tt.name, w.Code, w.Body.String())
+		}
+	}
+	if restored == nil || restored.Accounts[0].Balance != 5 {
+		t.Errorf("Expected the valid snapshot to be restored, got %+v", restored)
+	}
+}
+
+func TestRestore_BulkBodyLimit(t *testing.T) {
+	snapshots := &MockSnapshotService{RestoreFunc: func(s *domain.Snapshot) error { return nil }}
+	valid := domain.Snapshot{Version: domain.SnapshotVersion, Accounts: []domain.Account{{ID: "100", Balance: 5}}}
+	valid.Checksum = valid.Sum()
+	body, _ := json.Marshal(valid)
+
+	for limit, want := range map[int64]int{1024: http.StatusOK, 64: http.StatusRequestEntityTooLarge} {
+		cfg := DefaultServerConfig()
+		cfg.MaxBodyBytes = 16
+		cfg.MaxBulkBodyBytes = limit
+		h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithSnapshotService(snapshots), WithServerConfig(cfg))
+
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(string(body))))
+
+		if w.Code != want {
+			t.Errorf("Expected status %d with a bulk limit of %d, got %d", want, limit, w.Code)
+		}
+	}
+}
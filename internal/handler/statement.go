@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const statementTimeLayout = "2006-01-02 15:04:05.000"
+
+var statementFormats = map[string]func(io.Writer) statementEncoder{
+	"json": func(w io.Writer) statementEncoder { return &jsonStatement{w: w} },
+	"csv":  func(w io.Writer) statementEncoder { return &csvStatement{w: csv.NewWriter(w)} },
+	"txt":  func(w io.Writer) statementEncoder { return &textStatement{w: w} },
+}
+
+var statementContentTypes = map[string]string{
+	"json": "application/json",
+	"csv":  "text/csv; charset=utf-8",
+	"txt":  "text/plain; charset=utf-8",
+}
+
+type statementEncoder interface {
+	domain.StatementWriter
+	flush() error
+}
+
+// handleStatement streams the statement as it is read from the journal.
+// Once the first byte is out the status can no longer change, so a failure
+// midway aborts the connection and the client sees a truncated body.
+func (h *HTTPHandler) handleStatement(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	query := r.URL.Query()
+	var from time.Time
+	to := time.Now()
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := query.Get(param.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "invalid %s", param.name)
+				return
+			}
+			*param.t = t
+		}
+	}
+	if !from.Before(to) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "from must be before to")
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	newEncoder, ok := statementFormats[format]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid format")
+		return
+	}
+	err := h.authorize(r.Context(), func(p *domain.Principal) bool {
+		return p.CanRead(id)
+	})
+	if err != nil {
+		h.writeAuthError(w, err)
+		return
+	}
+
+	sw := &statementResponse{stream: newStreamResponse(w, statementContentTypes[format], h.serverConfig.WriteTimeout), enc: newEncoder(w)}
+	err = h.ledgerService.Statement(r.Context(), id, from.UTC(), to.UTC(), sw)
+	if err == nil {
+		err = sw.enc.flush()
+	}
+	if err == nil {
+		return
+	}
+	if sw.stream.started {
+		sw.stream.abort(r, "Statement aborted", "account_id", id, "error", err)
+	}
+	if writeContextError(w, r, err) {
+		return
+	}
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "0")
+		return
+	}
+	slog.ErrorContext(r.Context(), "Error generating statement", "account_id", id, "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// statementResponse starts the response on Begin and streams the lines
+// through enc.
+type statementResponse struct {
+	stream *streamResponse
+	enc    statementEncoder
+}
+
+func (s *statementResponse) Begin(header domain.StatementHeader) error {
+	s.stream.start()
+	return s.enc.Begin(header)
+}
+
+func (s *statementResponse) Line(line domain.StatementLine) error {
+	if err := s.enc.Line(line); err != nil {
+		return err
+	}
+	return s.stream.written(s.enc.flush)
+}
+
+func (s *statementResponse) End(summary domain.StatementSummary) error {
+	return s.enc.End(summary)
+}
+
+// jsonStatement writes one object, with the header fields first, then
+// "lines", then the summary fields.
+type jsonStatement struct {
+	w     io.Writer
+	lines int
+}
+
+func (s *jsonStatement) Begin(header domain.StatementHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	data = append(bytes.TrimSuffix(data, []byte("}")), `,"lines":[`...)
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *jsonStatement) Line(line domain.StatementLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if s.lines > 0 {
+		data = append([]byte{','}, data...)
+	}
+	s.lines++
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *jsonStatement) End(summary domain.StatementSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	data = append([]byte("],"), bytes.TrimPrefix(data, []byte("{"))...)
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+func (s *jsonStatement) flush() error { return nil }
+
+// csvStatement writes one row per record, told apart by the first column.
+type csvStatement struct {
+	w *csv.Writer
+}
+
+func (s *csvStatement) Begin(header domain.StatementHeader) error {
+	s.w.Write([]string{"record", "time", "seq", "type", "counterparty", "credit", "debit", "balance", "count"})
+	return s.w.Write([]string{"opening", formatStatementTime(header.From, time.RFC3339Nano), "", "", "", "", "", strconv.Itoa(header.OpeningBalance), ""})
+}
+
+func (s *csvStatement) Line(line domain.StatementLine) error {
+	credit, debit := splitAmount(line.Amount)
+	return s.w.Write([]string{
+		"posting",
+		line.Time.Format(time.RFC3339Nano),
+		strconv.FormatUint(line.Seq, 10),
+		line.Type,
+		line.Counterparty,
+		strconv.Itoa(credit),
+		strconv.Itoa(debit),
+		strconv.Itoa(line.Balance),
+		"",
+	})
+}
+
+func (s *csvStatement) End(summary domain.StatementSummary) error {
+	for _, t := range summary.Totals {
+		s.w.Write([]string{"total", "", "", t.Type, "", strconv.Itoa(t.Credits), strconv.Itoa(t.Debits), "", strconv.Itoa(t.Count)})
+	}
+	return s.w.Write([]string{"closing", "", "", "", "", "", "", strconv.Itoa(summary.ClosingBalance), ""})
+}
+
+func (s *csvStatement) flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// textStatement writes fixed-width columns, so it needs no buffering to
+// line them up.
+type textStatement struct {
+	w io.Writer
+}
+
+const textStatementRow = "%-8s  %-23s  %-10s  %-16s  %12s  %12s  %12s\n"
+
+func (s *textStatement) Begin(header domain.StatementHeader) error {
+	from := formatStatementTime(header.From, statementTimeLayout)
+	if from == "" {
+		from = "first posting"
+	}
+	_, err := fmt.Fprintf(s.w, "Statement for account %s\nPeriod: %s to %s\nOpening balance: %d\n\n"+textStatementRow,
+		header.AccountID,
+		from,
+		formatStatementTime(header.To, statementTimeLayout),
+		header.OpeningBalance,
+		"SEQ", "TIME", "TYPE", "COUNTERPARTY", "CREDIT", "DEBIT", "BALANCE",
+	)
+	return err
+}
+
+func (s *textStatement) Line(line domain.StatementLine) error {
+	credit, debit := "", ""
+	if line.Amount >= 0 {
+		credit = strconv.Itoa(line.Amount)
+	} else {
+		debit = strconv.Itoa(-line.Amount)
+	}
+	_, err := fmt.Fprintf(s.w, textStatementRow,
+		strconv.FormatUint(line.Seq, 10),
+		line.Time.Format(statementTimeLayout),
+		line.Type,
+		line.Counterparty,
+		credit,
+		debit,
+		strconv.Itoa(line.Balance),
+	)
+	return err
+}
+
+func (s *textStatement) End(summary domain.StatementSummary) error {
+	if _, err := fmt.Fprintf(s.w, "\n%-10s  %8s  %12s  %12s\n", "TYPE", "COUNT", "CREDITS", "DEBITS"); err != nil {
+		return err
+	}
+	for _, t := range summary.Totals {
+		if _, err := fmt.Fprintf(s.w, "%-10s  %8d  %12d  %12d\n", t.Type, t.Count, t.Credits, t.Debits); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(s.w, "\nClosing balance: %d\n", summary.ClosingBalance)
+	return err
+}
+
+func (s *textStatement) flush() error { return nil }
+
+func splitAmount(amount int) (credit, debit int) {
+	if amount >= 0 {
+		return amount, 0
+	}
+	return 0, -amount
+}
+
+// formatStatementTime leaves an unbounded start of period blank.
+func formatStatementTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+var statementTime = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func writeTestStatement(id string, from, to time.Time, w domain.StatementWriter) error {
+	if id != "100" {
+		return domain.ErrAccountNotFound
+	}
+	w.Begin(domain.StatementHeader{AccountID: id, From: from, To: to, OpeningBalance: 10})
+	w.Line(domain.StatementLine{Posting: domain.Posting{Seq: 1, Time: statementTime, Type: "deposit", Amount: 5}, Balance: 15})
+	w.Line(domain.StatementLine{Posting: domain.Posting{Seq: 2, Time: statementTime, Type: "transfer", Counterparty: "300", Amount: -7}, Balance: 8})
+	return w.End(domain.StatementSummary{
+		Totals: []domain.StatementTotal{
+			{Type: "deposit", Count: 1, Credits: 5},
+			{Type: "transfer", Count: 1, Debits: 7},
+		},
+		ClosingBalance: 8,
+	})
+}
+
+func newStatementHandler() *HTTPHandler {
+	ledger := &MockLedgerService{StatementFunc: writeTestStatement}
+	return NewAccountHTTPHandler(&MockService{}, &MockService{}, WithLedgerService(ledger))
+}
+
+func TestStatement_JSON(t *testing.T) {
+	h := newStatementHandler()
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/100/statement?from=2026-10-01T00:00:00Z&to=2026-10-02T00:00:00Z", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected 200 JSON, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var got struct {
+		domain.StatementHeader
+		domain.StatementSummary
+		Lines []domain.StatementLine `json:"lines"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, w.Body.String())
+	}
+	if got.AccountID != "100" || got.OpeningBalance != 10 || got.ClosingBalance != 8 {
+		t.Errorf("Expected balances 10 to 8 for 100, got %+v", got)
+	}
+	if len(got.Lines) != 2 || got.Lines[1].Counterparty != "300" || got.Lines[1].Balance != 8 {
+		t.Errorf("Expected two lines, got %+v", got.Lines)
+	}
+	if len(got.Totals) != 2 || got.Totals[1].Debits != 7 {
+		t.Errorf("Expected two totals, got %+v", got.Totals)
+	}
+}
+
+func TestStatement_CSV(t *testing.T) {
+	h := newStatementHandler()
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/100/statement?format=csv", nil))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	var kinds []string
+	for _, r := range records {
+		kinds = append(kinds, r[0])
+	}
+	if got := strings.Join(kinds, ","); got != "record,opening,posting,posting,total,total,closing" {
+		t.Errorf("Expected header, opening, postings, totals and closing rows, got %s", got)
+	}
+	if r := records[3]; r[4] != "300" || r[5] != "0" || r[6] != "7" || r[7] != "8" {
+		t.Errorf("Expected a debit of 7 to 300 leaving 8, got %v", r)
+	}
+	if r := records[1]; r[1] != "" || r[7] != "10" {
+		t.Errorf("Expected an open-ended opening row with 10, got %v", r)
+	}
+}
+
+func TestStatement_Text(t *testing.T) {
+	h := newStatementHandler()
+
+	w := httptest.NewRecorder()
+	h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/100/statement?format=txt", nil))
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"Statement for account 100\n",
+		"Opening balance: 10\n",
+		"2         2026-10-01 12:00:00.000  transfer    300                                        7             8\n",
+		"Closing balance: 8\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in statement, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestStatement_Errors(t *testing.T) {
+	h := newStatementHandler()
+
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"/accounts/200/statement", http.StatusNotFound},
+		{"/accounts/100/statement?format=pdf", http.StatusBadRequest},
+		{"/accounts/100/statement?from=yesterday", http.StatusBadRequest},
+		{"/accounts/100/statement?from=2026-10-02T00:00:00Z&to=2026-10-01T00:00:00Z", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.query, nil))
+
+		if w.Code != tt.wantCode {
+			t.Errorf("Expected status %d for %s, got %d", tt.wantCode, tt.query, w.Code)
+		}
+	}
+}
+
+func TestStatement_AbortsMidStream(t *testing.T) {
+	ledger := &MockLedgerService{
+		StatementFunc: func(id string, from, to time.Time, w domain.StatementWriter) error {
+			w.Begin(domain.StatementHeader{AccountID: id})
+			return errors.New("storage failed")
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithLedgerService(ledger))
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("Expected the handler to abort the response, got %v", r)
+		}
+	}()
+	h.routes().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/100/statement", nil))
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing starts a server span for every request.
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(h *HTTPHandler) {
+		h.tracerProvider = tp
+	}
+}
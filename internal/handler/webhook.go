@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// WithWebhookService enables the webhook management endpoints.
+func WithWebhookService(webhookService domain.WebhookService) Option {
+	return func(h *HTTPHandler) {
+		h.webhookService = webhookService
+	}
+}
+
+func (h *HTTPHandler) registerWebhookRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /webhooks", h.requireRole(h.handleRegisterWebhook, domain.RoleAdmin))
+	mux.HandleFunc("GET /webhooks", h.requireRole(h.handleListWebhooks, domain.RoleAdmin))
+	mux.HandleFunc("DELETE /webhooks/{id}", h.requireRole(h.handleDeleteWebhook, domain.RoleAdmin))
+	mux.HandleFunc("GET /webhooks/deliveries", h.requireRole(h.handleListWebhookDeliveries, domain.RoleAdmin))
+	mux.HandleFunc("GET /webhooks/dead-letters", h.requireRole(h.handleListWebhookDeadLetters, domain.RoleAdmin))
+	mux.HandleFunc("POST /webhooks/dead-letters/{id}/retry", h.requireRole(h.handleRedeliverWebhook, domain.RoleAdmin))
+}
+
+func (h *HTTPHandler) handleRegisterWebhook(w http.ResponseWriter, r *http.Request) {
+	var req domain.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		httpErrors := translateValidationErrors(err, r.Header.Get("Accept-Language"))
+		body, _ := json.Marshal(httpErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(body)
+		return
+	}
+	hook, err := h.webhookService.Register(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	redacted := *hook
+	redacted.Secret = ""
+	h.recordAudit(r, domain.AuditWebhookCreate, hook.ID, nil, redacted)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+func (h *HTTPHandler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.webhookService.List()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hooks)
+}
+
+func (h *HTTPHandler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhookService.Delete(r.PathValue("id")); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	h.recordAudit(r, domain.AuditWebhookDelete, r.PathValue("id"), nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HTTPHandler) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.webhookService.Deliveries(r.URL.Query().Get("webhook_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func (h *HTTPHandler) handleListWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deadLetters, err := h.webhookService.DeadLetters()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deadLetters)
+}
+
+func (h *HTTPHandler) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhookService.Redeliver(r.PathValue("id")); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	h.recordAudit(r, domain.AuditWebhookRetry, r.PathValue("id"), nil, nil)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrWebhookNotFound) || errors.Is(err, domain.ErrDeadLetterNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
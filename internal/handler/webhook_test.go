@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type MockWebhookService struct {
+	RegisterFunc  func(domain.Webhook) (*domain.Webhook, error)
+	DeleteFunc    func(string) error
+	RedeliverFunc func(string) error
+}
+
+func (m *MockWebhookService) Register(hook domain.Webhook) (*domain.Webhook, error) {
+	return m.RegisterFunc(hook)
+}
+
+func (m *MockWebhookService) List() ([]domain.Webhook, error) {
+	return nil, nil
+}
+
+func (m *MockWebhookService) Delete(id string) error {
+	return m.DeleteFunc(id)
+}
+
+func (m *MockWebhookService) Deliveries(webhookID string) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (m *MockWebhookService) DeadLetters() ([]domain.WebhookDeadLetter, error) {
+	return nil, nil
+}
+
+func (m *MockWebhookService) Redeliver(id string) error {
+	return m.RedeliverFunc(id)
+}
+
+func TestRegisterWebhook(t *testing.T) {
+	mockWebhooks := &MockWebhookService{
+		RegisterFunc: func(hook domain.Webhook) (*domain.Webhook, error) {
+			hook.ID = "wh_1"
+			return &hook, nil
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithWebhookService(mockWebhooks))
+
+	body := []byte(`{"url":"https://partner.example/hook", "event_types":["transfer"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	var hook domain.Webhook
+	json.Unmarshal(w.Body.Bytes(), &hook)
+	if hook.ID != "wh_1" {
+		t.Errorf("Expected webhook ID wh_1, got %q", hook.ID)
+	}
+}
+
+func TestRegisterWebhook_InvalidEventType(t *testing.T) {
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithWebhookService(&MockWebhookService{}))
+
+	body := []byte(`{"url":"https://partner.example/hook", "event_types":["refund"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDeleteWebhook_NotFound(t *testing.T) {
+	mockWebhooks := &MockWebhookService{
+		DeleteFunc: func(id string) error {
+			return domain.ErrWebhookNotFound
+		},
+	}
+	h := NewAccountHTTPHandler(&MockService{}, &MockService{}, WithWebhookService(mockWebhooks))
+
+	req := httptest.NewRequest(http.MethodDelete, "/webhooks/wh_missing", nil)
+	w := httptest.NewRecorder()
+
+	h.routes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/logging"
+)
+
+// WebSocketConfig bounds the resources a single WebSocket client may use.
+type WebSocketConfig struct {
+	// MaxMessageSize is the largest inbound frame accepted, in bytes.
+	MaxMessageSize int64
+	// SendQueueSize is the number of outbound frames buffered per connection.
+	// Clients that fall further behind on account updates are disconnected.
+	SendQueueSize int
+	// MaxSubscriptions is the number of accounts a connection may watch.
+	MaxSubscriptions int
+	// PingInterval is how often the server pings the client.
+	PingInterval time.Duration
+	// PongTimeout is how long the server waits for any frame, including a
+	// pong, before dropping the connection. It must exceed PingInterval.
+	PongTimeout time.Duration
+	// WriteTimeout bounds a single frame write.
+	WriteTimeout time.Duration
+}
+
+func DefaultWebSocketConfig() WebSocketConfig {
+	return WebSocketConfig{
+		MaxMessageSize:   4096,
+		SendQueueSize:    64,
+		MaxSubscriptions: 100,
+		PingInterval:     30 * time.Second,
+		PongTimeout:      60 * time.Second,
+		WriteTimeout:     10 * time.Second,
+	}
+}
+
+const (
+	wsActionEvent       = "event"
+	wsActionSubscribe   = "subscribe"
+	wsActionUnsubscribe = "unsubscribe"
+
+	wsTypeResult       = "result"
+	wsTypeSubscribed   = "subscribed"
+	wsTypeUnsubscribed = "unsubscribed"
+	wsTypeAccount      = "account"
+	wsTypeError        = "error"
+
+	wsErrBadRequest        = "bad_request"
+	wsErrValidation        = "validation"
+	wsErrNotFound          = "not_found"
+	wsErrUnknownAction     = "unknown_action"
+	wsErrSubscriptionLimit = "subscription_limit"
+	wsErrTimeout           = "timeout"
+	wsErrForbidden         = "forbidden"
+	wsErrAccountFrozen     = "account_frozen"
+	wsErrRateLimited       = "rate_limited"
+	wsErrRiskDenied        = "risk_denied"
+	wsErrAccountBlocked    = "account_blocked"
+	wsErrPendingReview     = "pending_review"
+)
+
+// wsRequest is a client frame. ID is echoed back on the matching response so
+// clients can correlate requests and results.
+type wsRequest struct {
+	ID       string               `json:"id"`
+	Action   string               `json:"action"`
+	Event    *domain.EventRequest `json:"event,omitempty"`
+	Accounts []string             `json:"accounts,omitempty"`
+}
+
+// wsMessage is a server frame.
+type wsMessage struct {
+	ID       string                                   `json:"id,omitempty"`
+	Type     string                                   `json:"type"`
+	Result   *domain.EventResponse                    `json:"result,omitempty"`
+	Account  *domain.Account                          `json:"account,omitempty"`
+	Review   *domain.Review                           `json:"review,omitempty"`
+	Accounts []string                                 `json:"accounts,omitempty"`
+	Code     string                                   `json:"code,omitempty"`
+	Error    string                                   `json:"error,omitempty"`
+	Details  []validator.ValidationErrorsTranslations `json:"details,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type wsConn struct {
+	ws        *websocket.Conn
+	send      chan wsMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	slow      atomic.Bool
+	goingAway atomic.Bool
+	subs      map[string]struct{}
+	// client is the rate limit key of the upgrading request.
+	client string
+}
+
+func (c *wsConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// enqueue queues a frame without blocking. It reports false when the client
+// is too slow to keep up, in which case the connection is closed.
+func (c *wsConn) enqueue(msg wsMessage) bool {
+	select {
+	case c.send <- msg:
+		return true
+	case <-c.done:
+		return false
+	default:
+		c.slow.Store(true)
+		c.close()
+		return false
+	}
+}
+
+// reply queues a response frame, blocking until there is room. Since
+// requests are read one at a time, this stops reading from a client that is
+// not draining its responses.
+func (c *wsConn) reply(msg wsMessage) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	}
+}
+
+// wsHub tracks open connections and which accounts each one watches.
+type wsHub struct {
+	conns       map[*wsConn]struct{}
+	subscribers map[string]map[*wsConn]struct{}
+	mu          sync.RWMutex
+}
+
+func newWSHub() *wsHub {
+	return &wsHub{
+		conns:       make(map[*wsConn]struct{}),
+		subscribers: make(map[string]map[*wsConn]struct{}),
+	}
+}
+
+func (hub *wsHub) register(c *wsConn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	hub.conns[c] = struct{}{}
+}
+
+// unregister drops the connection and all of its subscriptions.
+func (hub *wsHub) unregister(c *wsConn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	delete(hub.conns, c)
+	for accountID := range c.subs {
+		conns := hub.subscribers[accountID]
+		delete(conns, c)
+		if len(conns) == 0 {
+			delete(hub.subscribers, accountID)
+		}
+	}
+}
+
+func (hub *wsHub) subscribe(c *wsConn, accountID string) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	conns, ok := hub.subscribers[accountID]
+	if !ok {
+		conns = make(map[*wsConn]struct{})
+		hub.subscribers[accountID] = conns
+	}
+	conns[c] = struct{}{}
+}
+
+func (hub *wsHub) unsubscribe(c *wsConn, accountID string) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	conns, ok := hub.subscribers[accountID]
+	if !ok {
+		return
+	}
+	delete(conns, c)
+	if len(conns) == 0 {
+		delete(hub.subscribers, accountID)
+	}
+}
+
+// closeAll disconnects every client with a going-away close frame.
+func (hub *wsHub) closeAll() {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	for c := range hub.conns {
+		c.goingAway.Store(true)
+		c.close()
+	}
+}
+
+func (hub *wsHub) publish(account domain.Account) {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	for c := range hub.subscribers[account.ID] {
+		acc := account
+		c.enqueue(wsMessage{Type: wsTypeAccount, Account: &acc})
+	}
+}
+
+func (h *HTTPHandler) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response.
+		return
+	}
+
+	c := &wsConn{
+		ws:     ws,
+		send:   make(chan wsMessage, h.wsConfig.SendQueueSize),
+		done:   make(chan struct{}),
+		subs:   make(map[string]struct{}),
+		client: clientKey(r),
+	}
+
+	h.hub.register(c)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		h.wsWriteLoop(c)
+	}()
+
+	h.wsReadLoop(r.Context(), c, r.Header.Get("Accept-Language"))
+
+	h.hub.unregister(c)
+	c.close()
+	wg.Wait()
+	ws.Close()
+}
+
+func (h *HTTPHandler) wsReadLoop(ctx context.Context, c *wsConn, lang string) {
+	c.ws.SetReadLimit(h.wsConfig.MaxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(h.wsConfig.PongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(h.wsConfig.PongTimeout))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(h.wsConfig.PongTimeout))
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.reply(wsMessage{Type: wsTypeError, Code: wsErrBadRequest, Error: "malformed frame"})
+			continue
+		}
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+		c.reply(h.handleWSRequest(ctx, c, req, lang))
+	}
+}
+
+func (h *HTTPHandler) handleWSRequest(ctx context.Context, c *wsConn, req wsRequest, lang string) wsMessage {
+	switch req.Action {
+	case wsActionEvent:
+		if req.Event == nil {
+			return wsError(req.ID, wsErrBadRequest, "missing event")
+		}
+		if err := h.validate.Struct(req.Event); err != nil {
+			msg := wsError(req.ID, wsErrValidation, "invalid event")
+			msg.Details = translateValidationErrors(err, lang)
+			return msg
+		}
+		err := h.authorize(ctx, func(p *domain.Principal) bool {
+			return p.CanSubmit(*req.Event)
+		})
+		if err != nil {
+			return wsError(req.ID, wsErrForbidden, err.Error())
+		}
+		if !h.clientLimiter.Allow(c.client).Allowed || !h.allowAccount(*req.Event).Allowed {
+			return wsError(req.ID, wsErrRateLimited, "rate limit exceeded")
+		}
+		if req.ID != "" {
+			ctx = logging.WithRequestID(ctx, logging.RequestID(ctx)+"/"+req.ID)
+		}
+		if timeout := h.serverConfig.RequestTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		resp, err := h.eventService.ProcessEvent(ctx, *req.Event)
+		if isContextError(err) {
+			return wsError(req.ID, wsErrTimeout, err.Error())
+		}
+		if errors.Is(err, domain.ErrAccountFrozen) {
+			return wsError(req.ID, wsErrAccountFrozen, err.Error())
+		}
+		if errors.Is(err, domain.ErrAccountBlocked) {
+			return wsError(req.ID, wsErrAccountBlocked, domain.ErrAccountBlocked.Error())
+		}
+		var riskErr *domain.RiskError
+		if errors.As(err, &riskErr) {
+			if riskErr.Review != nil {
+				msg := wsError(req.ID, wsErrPendingReview, err.Error())
+				msg.Review = riskErr.Review
+				return msg
+			}
+			return wsError(req.ID, wsErrRiskDenied, err.Error())
+		}
+		if err != nil {
+			return wsError(req.ID, wsErrNotFound, err.Error())
+		}
+		return wsMessage{ID: req.ID, Type: wsTypeResult, Result: resp.Clone()}
+	case wsActionSubscribe:
+		if len(req.Accounts) == 0 {
+			return wsError(req.ID, wsErrBadRequest, "missing accounts")
+		}
+		err := h.authorize(ctx, func(p *domain.Principal) bool {
+			return !slices.ContainsFunc(req.Accounts, func(id string) bool { return !p.CanRead(id) })
+		})
+		if err != nil {
+			return wsError(req.ID, wsErrForbidden, err.Error())
+		}
+		added := 0
+		for _, accountID := range req.Accounts {
+			if _, ok := c.subs[accountID]; !ok {
+				added++
+			}
+		}
+		if len(c.subs)+added > h.wsConfig.MaxSubscriptions {
+			return wsError(req.ID, wsErrSubscriptionLimit, "too many subscriptions")
+		}
+		for _, accountID := range req.Accounts {
+			c.subs[accountID] = struct{}{}
+			h.hub.subscribe(c, accountID)
+		}
+		return wsMessage{ID: req.ID, Type: wsTypeSubscribed, Accounts: req.Accounts}
+	case wsActionUnsubscribe:
+		for _, accountID := range req.Accounts {
+			delete(c.subs, accountID)
+			h.hub.unsubscribe(c, accountID)
+		}
+		return wsMessage{ID: req.ID, Type: wsTypeUnsubscribed, Accounts: req.Accounts}
+	default:
+		return wsError(req.ID, wsErrUnknownAction, "unknown action: "+req.Action)
+	}
+}
+
+func (h *HTTPHandler) wsWriteLoop(c *wsConn) {
+	ticker := time.NewTicker(h.wsConfig.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(h.wsConfig.WriteTimeout))
+			if err := c.ws.WriteJSON(msg); err != nil {
+				c.close()
+				c.ws.Close()
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(h.wsConfig.WriteTimeout)
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				c.close()
+				c.ws.Close()
+				return
+			}
+		case <-c.done:
+			// Closing the socket unblocks the read loop when the connection
+			// was dropped for being too slow.
+			deadline := time.Now().Add(h.wsConfig.WriteTimeout)
+			switch {
+			case c.slow.Load():
+				c.ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "send queue full"), deadline)
+			case c.goingAway.Load():
+				c.ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), deadline)
+			}
+			c.ws.Close()
+			return
+		}
+	}
+}
+
+func wsError(id, code, message string) wsMessage {
+	return wsMessage{ID: id, Type: wsTypeError, Code: code, Error: message}
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+func newWSTestServer(t *testing.T, opts ...Option) (*httptest.Server, *service.EventService) {
+	t.Helper()
+
+	repo := repository.NewInMemoryRepository()
+	accountService := service.NewAccountService(repo)
+	eventService := service.NewEventService(accountService)
+	h := NewAccountHTTPHandler(accountService, eventService, opts...)
+	eventService.AddListener(h)
+
+	server := httptest.NewServer(h.routes())
+	t.Cleanup(server.Close)
+	return server, eventService
+}
+
+func dialWS(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Expected no error dialing: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+
+	var msg wsMessage
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Expected no error reading frame: %v", err)
+	}
+	return msg
+}
+
+func TestWebSocket_EventCorrelation(t *testing.T) {
+	server, _ := newWSTestServer(t)
+	conn := dialWS(t, server)
+
+	conn.WriteJSON(wsRequest{
+		ID:     "req-1",
+		Action: wsActionEvent,
+		Event:  &domain.EventRequest{Type: "deposit", Destination: "100", Amount: 10},
+	})
+
+	msg := readWS(t, conn)
+	if msg.ID != "req-1" || msg.Type != wsTypeResult {
+		t.Fatalf("Expected result for req-1, got %+v", msg)
+	}
+	if msg.Result.Destination.Balance != 10 {
+		t.Errorf("Expected balance 10, got %d", msg.Result.Destination.Balance)
+	}
+
+	conn.WriteJSON(wsRequest{
+		ID:     "req-2",
+		Action: wsActionEvent,
+		Event:  &domain.EventRequest{Type: "withdraw", Origin: "200", Amount: 10},
+	})
+
+	msg = readWS(t, conn)
+	if msg.ID != "req-2" || msg.Code != wsErrNotFound {
+		t.Errorf("Expected not_found for req-2, got %+v", msg)
+	}
+}
+
+func TestWebSocket_ValidationError(t *testing.T) {
+	server, _ := newWSTestServer(t)
+	conn := dialWS(t, server)
+
+	conn.WriteJSON(wsRequest{
+		ID:     "bad",
+		Action: wsActionEvent,
+		Event:  &domain.EventRequest{Type: "steal", Amount: 10},
+	})
+
+	msg := readWS(t, conn)
+	if msg.Code != wsErrValidation {
+		t.Errorf("Expected validation error, got %+v", msg)
+	}
+	if len(msg.Details) == 0 {
+		t.Errorf("Expected validation details")
+	}
+}
+
+func TestWebSocket_SubscriptionReceivesUpdates(t *testing.T) {
+	server, eventService := newWSTestServer(t)
+	conn := dialWS(t, server)
+
+	conn.WriteJSON(wsRequest{ID: "sub", Action: wsActionSubscribe, Accounts: []string{"300"}})
+	if msg := readWS(t, conn); msg.Type != wsTypeSubscribed {
+		t.Fatalf("Expected subscribed ack, got %+v", msg)
+	}
+
+	// Events from any source, not just this connection, are pushed.
+	eventService.ProcessEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 5})
+	eventService.ProcessEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "300", Amount: 7})
+
+	msg := readWS(t, conn)
+	if msg.Type != wsTypeAccount || msg.Account.ID != "300" || msg.Account.Balance != 7 {
+		t.Errorf("Expected update for account 300, got %+v", msg)
+	}
+}
+
+func TestWebSocket_SubscriptionLimit(t *testing.T) {
+	cfg := DefaultWebSocketConfig()
+	cfg.MaxSubscriptions = 1
+	server, _ := newWSTestServer(t, WithWebSocketConfig(cfg))
+	conn := dialWS(t, server)
+
+	conn.WriteJSON(wsRequest{ID: "sub", Action: wsActionSubscribe, Accounts: []string{"100", "200"}})
+
+	msg := readWS(t, conn)
+	if msg.Code != wsErrSubscriptionLimit {
+		t.Errorf("Expected subscription_limit, got %+v", msg)
+	}
+}
+
+func TestWebSocket_SlowConsumerDisconnected(t *testing.T) {
+	cfg := DefaultWebSocketConfig()
+	cfg.SendQueueSize = 1
+	server, eventService := newWSTestServer(t, WithWebSocketConfig(cfg))
+	conn := dialWS(t, server)
+
+	conn.WriteJSON(wsRequest{ID: "sub", Action: wsActionSubscribe, Accounts: []string{"100"}})
+	readWS(t, conn)
+
+	for i := 0; i < 1000; i++ {
+		eventService.ProcessEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 1})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg wsMessage
+		err := conn.ReadJSON(&msg)
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+			t.Errorf("Expected policy violation close, got %v", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrDraining = errors.New("draining")
+
+// Check reports whether a dependency is ready to serve traffic.
+type Check func(ctx context.Context) error
+
+// Registry collects readiness checks from repositories and background
+// workers. The process is ready when every check passes and it is not
+// draining.
+type Registry struct {
+	checks   map[string]Check
+	mu       sync.RWMutex
+	draining atomic.Bool
+	timeout  time.Duration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:  make(map[string]Check),
+		timeout: 2 * time.Second,
+	}
+}
+
+// Register adds or replaces the check with the given name.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = check
+}
+
+// SetDraining marks the process as shutting down so it stops receiving new
+// traffic.
+func (r *Registry) SetDraining(draining bool) {
+	r.draining.Store(draining)
+}
+
+// Result is the outcome of a readiness probe. Checks maps each check name to
+// "ok" or its error message.
+type Result struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready runs every check concurrently, each bounded by the registry timeout.
+func (r *Registry) Ready(ctx context.Context) Result {
+	r.mu.RLock()
+	checks := maps.Clone(r.checks)
+	r.mu.RUnlock()
+
+	result := Result{Ready: true, Checks: make(map[string]string, len(checks)+1)}
+	if r.draining.Load() {
+		result.Ready = false
+		result.Checks["draining"] = ErrDraining.Error()
+	}
+
+	type outcome struct {
+		name string
+		err  error
+	}
+	outcomes := make(chan outcome, len(checks))
+	for _, name := range slices.Sorted(maps.Keys(checks)) {
+		go func(name string, check Check) {
+			checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+			outcomes <- outcome{name: name, err: check(checkCtx)}
+		}(name, checks[name])
+	}
+	for range checks {
+		o := <-outcomes
+		if o.err != nil {
+			result.Ready = false
+			result.Checks[o.name] = o.err.Error()
+			continue
+		}
+		result.Checks[o.name] = "ok"
+	}
+	return result
+}
+
+// Gate is a check that fails until Open is called, for one-off startup work
+// such as replaying a write-ahead log.
+type Gate struct {
+	open   atomic.Bool
+	reason string
+}
+
+func NewGate(reason string) *Gate {
+	return &Gate{reason: reason}
+}
+
+func (g *Gate) Open() {
+	g.open.Store(true)
+}
+
+func (g *Gate) Check(ctx context.Context) error {
+	if !g.open.Load() {
+		return errors.New(g.reason)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReady_AllChecksPass(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("repository", func(ctx context.Context) error { return nil })
+
+	result := registry.Ready(context.Background())
+
+	if !result.Ready {
+		t.Errorf("Expected ready, got %+v", result)
+	}
+	if result.Checks["repository"] != "ok" {
+		t.Errorf("Expected repository ok, got %q", result.Checks["repository"])
+	}
+}
+
+func TestReady_FailingCheck(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("repository", func(ctx context.Context) error { return nil })
+	registry.Register("outbox", func(ctx context.Context) error { return errors.New("unreachable") })
+
+	result := registry.Ready(context.Background())
+
+	if result.Ready {
+		t.Errorf("Expected not ready")
+	}
+	if result.Checks["outbox"] != "unreachable" {
+		t.Errorf("Expected outbox error, got %q", result.Checks["outbox"])
+	}
+}
+
+func TestReady_SlowCheckTimesOut(t *testing.T) {
+	registry := NewRegistry()
+	registry.timeout = 10 * time.Millisecond
+	registry.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if result := registry.Ready(context.Background()); result.Ready {
+		t.Errorf("Expected not ready")
+	}
+}
+
+func TestReady_Draining(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetDraining(true)
+
+	result := registry.Ready(context.Background())
+
+	if result.Ready || result.Checks["draining"] == "" {
+		t.Errorf("Expected draining to fail readiness, got %+v", result)
+	}
+}
+
+func TestGate(t *testing.T) {
+	gate := NewGate("replaying log")
+
+	if err := gate.Check(context.Background()); err == nil {
+		t.Errorf("Expected closed gate to fail")
+	}
+	gate.Open()
+	if err := gate.Check(context.Background()); err != nil {
+		t.Errorf("Expected open gate to pass: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package health
+
+import "runtime/debug"
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo reads the module version and VCS stamp embedded by the Go
+// toolchain. Fields are empty when the binary was built without VCS
+// information, e.g. by go run or go test.
+func ReadBuildInfo() BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{Version: "unknown"}
+	}
+
+	build := BuildInfo{
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.Time = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ctxKey struct{}
+
+// WithRequestID returns a context whose log records carry the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// accountKeys are the attribute keys holding account IDs.
+var accountKeys = []string{"account_id", "origin", "destination"}
+
+type Options struct {
+	Level slog.Level
+	// MaskAccounts replaces all but the last two characters of account IDs.
+	MaskAccounts bool
+}
+
+// New returns a JSON logger that adds the request ID and trace IDs from the
+// context to every record logged with one of the *Context methods.
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	if opts.MaskAccounts {
+		handlerOpts.ReplaceAttr = maskAccounts
+	}
+	return slog.New(&contextHandler{Handler: slog.NewJSONHandler(w, handlerOpts)})
+}
+
+func maskAccounts(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindString && slices.Contains(accountKeys, attr.Key) {
+		return slog.String(attr.Key, MaskAccount(attr.Value.String()))
+	}
+	return attr
+}
+
+// MaskAccount keeps only the last two characters of an account ID.
+func MaskAccount(id string) string {
+	if len(id) <= 2 {
+		return strings.Repeat("*", len(id))
+	}
+	return strings.Repeat("*", len(id)-2) + id[len(id)-2:]
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: slog.LevelInfo})
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	logger.With("component", "test").InfoContext(ctx, "Deposit applied", "amount", 10)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if record["request_id"] != "req-123" {
+		t.Errorf("Expected request_id req-123, got %v", record["request_id"])
+	}
+	if record["component"] != "test" {
+		t.Errorf("Expected logger attributes to be kept, got %v", record)
+	}
+}
+
+func TestLoggerMasksAccounts(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: slog.LevelInfo, MaskAccounts: true})
+
+	logger.Info("Transfer applied", "origin", "123456", "destination", "7", "amount", 10)
+
+	var record map[string]any
+	json.Unmarshal(buf.Bytes(), &record)
+	if record["origin"] != "****56" || record["destination"] != "*" {
+		t.Errorf("Expected masked accounts, got %v", record)
+	}
+	if record["amount"] != float64(10) {
+		t.Errorf("Expected amount to be untouched, got %v", record["amount"])
+	}
+}
+
+func TestLoggerAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: slog.LevelInfo})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	logger.InfoContext(ctx, "Deposit applied")
+
+	var record map[string]any
+	json.Unmarshal(buf.Bytes(), &record)
+	if record["trace_id"] != traceID.String() || record["span_id"] != spanID.String() {
+		t.Errorf("Expected trace and span IDs, got %v", record)
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// AccountService records event counts and amounts around another
+// domain.AccountService.
+type AccountService struct {
+	next    domain.AccountService
+	metrics *Metrics
+}
+
+func NewAccountService(next domain.AccountService, m *Metrics) *AccountService {
+	return &AccountService{next: next, metrics: m}
+}
+
+func (s *AccountService) GetBalance(ctx context.Context, id string) (int, error) {
+	return s.next.GetBalance(ctx, id)
+}
+
+func (s *AccountService) Deposit(ctx context.Context, id string, amount int) (*domain.Account, error) {
+	account, err := s.next.Deposit(ctx, id, amount)
+	s.metrics.observeEvent("deposit", amount, err)
+	return account, err
+}
+
+func (s *AccountService) Withdraw(ctx context.Context, id string, amount int) (*domain.Account, error) {
+	account, err := s.next.Withdraw(ctx, id, amount)
+	s.metrics.observeEvent("withdraw", amount, err)
+	return account, err
+}
+
+func (s *AccountService) SetFrozen(ctx context.Context, id string, frozen bool) (*domain.Account, error) {
+	return s.next.SetFrozen(ctx, id, frozen)
+}
+
+func (s *AccountService) Transfer(ctx context.Context, originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
+	origin, destination, err := s.next.Transfer(ctx, originID, destinationID, amount)
+	s.metrics.observeEvent("transfer", amount, err)
+	return origin, destination, err
+}
+
+func (s *AccountService) Reset(ctx context.Context) error {
+	return s.next.Reset(ctx)
+}
+
+// AccountRepository records operation latencies around another
+// domain.AccountRepository.
+type AccountRepository struct {
+	next    domain.AccountRepository
+	metrics *Metrics
+}
+
+func NewAccountRepository(next domain.AccountRepository, m *Metrics) *AccountRepository {
+	return &AccountRepository{next: next, metrics: m}
+}
+
+func (r *AccountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	defer r.metrics.observeRepo("find_by_id", time.Now())
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *AccountRepository) Upsert(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	defer r.metrics.observeRepo("upsert", time.Now())
+	return r.next.Upsert(ctx, account)
+}
+
+func (r *AccountRepository) Reset(ctx context.Context) error {
+	defer r.metrics.observeRepo("reset", time.Now())
+	return r.next.Reset(ctx)
+}
+
+func (r *AccountRepository) Transaction(ctx context.Context, fn func(tx domain.AccountTx) error) error {
+	defer r.metrics.observeRepo("transaction", time.Now())
+	return r.next.Transaction(ctx, fn)
+}
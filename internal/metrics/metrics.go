@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/respwriter"
+)
+
+const namespace = "ipkiss"
+
+// Metrics owns a Prometheus registry and the collectors recorded by the
+// HTTP middleware and the service and repository decorators.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	events          *prometheus.CounterVec
+	eventAmount     *prometheus.CounterVec
+	repoDuration    *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Processed events by type and outcome.",
+		}, []string{"type", "outcome"}),
+		eventAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "event_amount_total",
+			Help:      "Sum of event amounts by type and outcome.",
+		}, []string{"type", "outcome"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_operation_duration_seconds",
+			Help:      "Repository operation latency by operation.",
+			Buckets:   []float64{.00001, .00005, .0001, .0005, .001, .005, .01, .05, .1},
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.events,
+		m.eventAmount,
+		m.repoDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// RegisterAccountCount exposes the number of accounts as a gauge, read from
+// count on every scrape.
+func (m *Metrics) RegisterAccountCount(count func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "accounts",
+		Help:      "Number of accounts.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records request counts and latencies. Routes are labelled by
+// their ServeMux pattern rather than the raw path, keeping cardinality
+// bounded.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := respwriter.Wrap(w)
+
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.Status())).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+func (m *Metrics) observeEvent(eventType string, amount int, err error) {
+	outcome := domain.Outcome(err)
+	m.events.WithLabelValues(eventType, outcome).Inc()
+	m.eventAmount.WithLabelValues(eventType, outcome).Add(float64(amount))
+}
+
+func (m *Metrics) observeRepo(operation string, start time.Time) {
+	m.repoDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+func TestMiddlewareLabelsByPattern(t *testing.T) {
+	m := New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := m.Middleware(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/webhooks/wh_1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/webhooks/wh_2", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("DELETE /webhooks/{id}", "DELETE", "204")); got != 2 {
+		t.Errorf("Expected 2 requests for the route pattern, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "GET", "404")); got != 1 {
+		t.Errorf("Expected 1 unmatched request, got %v", got)
+	}
+}
+
+func TestAccountServiceDecoratorOutcomes(t *testing.T) {
+	m := New()
+	repo := repository.NewInMemoryRepository()
+	accountService := NewAccountService(service.NewAccountService(NewAccountRepository(repo, m)), m)
+
+	accountService.Deposit(context.Background(), "100", 10)
+	accountService.Withdraw(context.Background(), "100", 50)
+	accountService.Withdraw(context.Background(), "200", 5)
+	accountService.Transfer(context.Background(), "100", "300", 4)
+
+	cases := []struct {
+		eventType, outcome string
+		count, amount      float64
+	}{
+		{"deposit", domain.OutcomeSuccess, 1, 10},
+		{"withdraw", domain.OutcomeInsufficientFunds, 1, 50},
+		{"withdraw", domain.OutcomeNotFound, 1, 5},
+		{"transfer", domain.OutcomeSuccess, 1, 4},
+	}
+	for _, c := range cases {
+		if got := testutil.ToFloat64(m.events.WithLabelValues(c.eventType, c.outcome)); got != c.count {
+			t.Errorf("Expected %v %s/%s events, got %v", c.count, c.eventType, c.outcome, got)
+		}
+		if got := testutil.ToFloat64(m.eventAmount.WithLabelValues(c.eventType, c.outcome)); got != c.amount {
+			t.Errorf("Expected %s/%s amount %v, got %v", c.eventType, c.outcome, c.amount, got)
+		}
+	}
+
+	if testutil.CollectAndCount(m.repoDuration) == 0 {
+		t.Errorf("Expected repository latencies to be recorded")
+	}
+}
+
+func TestHandlerExposesAccountCount(t *testing.T) {
+	m := New()
+	m.RegisterAccountCount(func() int { return 3 })
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.Contains(w.Body.String(), "ipkiss_accounts 3") {
+		t.Errorf("Expected account gauge in output")
+	}
+}
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// MemoryPublisher keeps published messages in memory. It is mostly useful in
+// tests.
+type MemoryPublisher struct {
+	messages []domain.OutboxMessage
+	mu       sync.RWMutex
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(msg domain.OutboxMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *MemoryPublisher) Messages() []domain.OutboxMessage {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return slices.Clone(p.messages)
+}
+
+// FilePublisher appends each message as a JSON line to a file, syncing after
+// every write.
+type FilePublisher struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(msg domain.OutboxMessage) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(line); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
+
+// HTTPPublisher POSTs each message as JSON to a sink. The message ID is sent
+// as the Idempotency-Key header so the sink can drop redeliveries.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPublisher) Publish(msg domain.OutboxMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", msg.ID)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
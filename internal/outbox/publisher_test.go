@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.ndjson")
+	publisher, err := NewFilePublisher(path)
+	if err != nil {
+		t.Fatalf("Expected no error opening file: %v", err)
+	}
+
+	publisher.Publish(domain.OutboxMessage{Seq: 1, ID: "msg_1"})
+	publisher.Publish(domain.OutboxMessage{Seq: 2, ID: "msg_2"})
+	publisher.Close()
+
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg domain.OutboxMessage
+		json.Unmarshal(scanner.Bytes(), &msg)
+		ids = append(ids, msg.ID)
+	}
+	if len(ids) != 2 || ids[0] != "msg_1" || ids[1] != "msg_2" {
+		t.Errorf("Expected msg_1 and msg_2, got %v", ids)
+	}
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	var idempotencyKey string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey = r.Header.Get("Idempotency-Key")
+	}))
+	defer sink.Close()
+
+	publisher := NewHTTPPublisher(sink.URL, time.Second)
+	if err := publisher.Publish(domain.OutboxMessage{ID: "msg_1"}); err != nil {
+		t.Errorf("Expected no error publishing: %v", err)
+	}
+	if idempotencyKey != "msg_1" {
+		t.Errorf("Expected Idempotency-Key msg_1, got %q", idempotencyKey)
+	}
+}
+
+func TestHTTPPublisher_ErrorStatus(t *testing.T) {
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer sink.Close()
+
+	publisher := NewHTTPPublisher(sink.URL, time.Second)
+	if err := publisher.Publish(domain.OutboxMessage{ID: "msg_1"}); err == nil {
+		t.Errorf("Expected error publishing to failing sink")
+	}
+}
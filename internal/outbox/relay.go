@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type RelayConfig struct {
+	// PollInterval is how often the outbox is drained.
+	PollInterval time.Duration
+	// BatchSize is the number of messages read per poll.
+	BatchSize int
+}
+
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval: 100 * time.Millisecond,
+		BatchSize:    100,
+	}
+}
+
+// Relay drains the outbox to a Publisher. A message is acknowledged only
+// after it has been published, so delivery is at-least-once. When a message
+// fails, later messages touching any of its accounts are held back until it
+// succeeds, which keeps delivery ordered per account without stalling
+// unrelated accounts.
+type Relay struct {
+	repo      domain.OutboxRepository
+	publisher domain.Publisher
+	cfg       RelayConfig
+
+	stop    chan struct{}
+	stopped atomic.Bool
+	lastErr atomic.Pointer[error]
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+func NewRelay(repo domain.OutboxRepository, publisher domain.Publisher, cfg RelayConfig) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		cfg:       cfg,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start runs the relay in the background until Stop is called.
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_, err := r.Drain()
+				r.lastErr.Store(&err)
+				if err != nil {
+					slog.Error("Error draining outbox", "error", err)
+				}
+			case <-r.stop:
+				if _, err := r.Drain(); err != nil {
+					slog.Error("Error draining outbox", "error", err)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Stop makes a final drain attempt and stops the relay.
+func (r *Relay) Stop() {
+	r.once.Do(func() {
+		r.stopped.Store(true)
+		close(r.stop)
+	})
+	r.wg.Wait()
+}
+
+// Check implements a readiness check, failing once the relay is stopped or
+// while the outbox cannot be read.
+func (r *Relay) Check(ctx context.Context) error {
+	if r.stopped.Load() {
+		return errors.New("outbox relay stopped")
+	}
+	if err := r.lastErr.Load(); err != nil && *err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Drain publishes up to one batch of pending messages and returns how many
+// were published. Messages held back by a failure are skipped, so Drain reads
+// on past them until the batch is full or the outbox is exhausted.
+func (r *Relay) Drain() (int, error) {
+	blocked := make(map[string]struct{})
+	published := 0
+	var after uint64
+	for published < r.cfg.BatchSize {
+		messages, err := r.repo.PendingOutbox(after, r.cfg.BatchSize)
+		if err != nil {
+			return published, err
+		}
+
+		var acked []uint64
+		for _, msg := range messages {
+			if published+len(acked) == r.cfg.BatchSize {
+				break
+			}
+			after = msg.Seq
+			if isBlocked(blocked, msg.Accounts) {
+				block(blocked, msg.Accounts)
+				continue
+			}
+			if err := r.publisher.Publish(msg); err != nil {
+				slog.Warn("Error publishing outbox message", "message_id", msg.ID, "seq", msg.Seq, "error", err)
+				block(blocked, msg.Accounts)
+				continue
+			}
+			acked = append(acked, msg.Seq)
+		}
+		if err := r.repo.AckOutbox(acked...); err != nil {
+			return published, err
+		}
+		published += len(acked)
+		if len(messages) < r.cfg.BatchSize {
+			break
+		}
+	}
+	return published, nil
+}
+
+func isBlocked(blocked map[string]struct{}, accounts []string) bool {
+	return slices.ContainsFunc(accounts, func(id string) bool {
+		_, ok := blocked[id]
+		return ok
+	})
+}
+
+func block(blocked map[string]struct{}, accounts []string) {
+	for _, id := range accounts {
+		blocked[id] = struct{}{}
+	}
+}
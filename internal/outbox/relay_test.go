@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+// flakyPublisher fails every message touching a failing account.
+type flakyPublisher struct {
+	MemoryPublisher
+	failing map[string]bool
+}
+
+func (p *flakyPublisher) Publish(msg domain.OutboxMessage) error {
+	for _, id := range msg.Accounts {
+		if p.failing[id] {
+			return errors.New("sink unavailable")
+		}
+	}
+	return p.MemoryPublisher.Publish(msg)
+}
+
+func TestRelayPublishesCommittedEvents(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accountService := service.NewAccountService(repo, service.WithOutbox())
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(repo, publisher, DefaultRelayConfig())
+
+	accountService.Deposit(context.Background(), "100", 10)
+	accountService.Transfer(context.Background(), "100", "300", 5)
+	// Rejected operations roll back and leave nothing in the outbox.
+	accountService.Withdraw(context.Background(), "100", 50)
+
+	published, err := relay.Drain()
+	if err != nil {
+		t.Fatalf("Expected no error draining: %v", err)
+	}
+	if published != 2 {
+		t.Fatalf("Expected 2 published messages, got %d", published)
+	}
+
+	messages := publisher.Messages()
+	if messages[0].Event.Type != "deposit" || messages[1].Event.Type != "transfer" {
+		t.Errorf("Expected deposit then transfer, got %+v", messages)
+	}
+	if messages[1].Result.Destination.Balance != 5 {
+		t.Errorf("Expected destination balance 5, got %d", messages[1].Result.Destination.Balance)
+	}
+
+	pending, _ := repo.PendingOutbox(0, 10)
+	if len(pending) != 0 {
+		t.Errorf("Expected empty outbox, got %d messages", len(pending))
+	}
+}
+
+func TestRelayKeepsPerAccountOrdering(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accountService := service.NewAccountService(repo, service.WithOutbox())
+	publisher := &flakyPublisher{failing: map[string]bool{"100": true}}
+	relay := NewRelay(repo, publisher, DefaultRelayConfig())
+
+	accountService.Deposit(context.Background(), "100", 10)
+	accountService.Deposit(context.Background(), "200", 10)
+	accountService.Transfer(context.Background(), "200", "100", 5)
+	accountService.Deposit(context.Background(), "300", 10)
+
+	relay.Drain()
+
+	// Only the messages that don't depend on account 100 go out.
+	messages := publisher.Messages()
+	if len(messages) != 2 || messages[0].Accounts[0] != "200" || messages[1].Accounts[0] != "300" {
+		t.Fatalf("Expected deposits to 200 and 300 only, got %+v", messages)
+	}
+
+	publisher.failing = nil
+	relay.Drain()
+
+	messages = publisher.Messages()
+	if len(messages) != 4 {
+		t.Fatalf("Expected 4 messages after recovery, got %d", len(messages))
+	}
+	if messages[2].Event.Destination != "100" || messages[3].Event.Type != "transfer" {
+		t.Errorf("Expected deposit to 100 before the transfer, got %+v", messages[2:])
+	}
+}
+
+func TestRelayDrainsPastFailingBatch(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accountService := service.NewAccountService(repo, service.WithOutbox())
+	publisher := &flakyPublisher{failing: map[string]bool{"100": true}}
+	relay := NewRelay(repo, publisher, RelayConfig{BatchSize: 2})
+
+	for range 3 {
+		accountService.Deposit(context.Background(), "100", 10)
+	}
+	accountService.Deposit(context.Background(), "200", 10)
+	accountService.Deposit(context.Background(), "300", 10)
+	accountService.Deposit(context.Background(), "400", 10)
+
+	published, err := relay.Drain()
+	if err != nil {
+		t.Fatalf("Expected no error draining: %v", err)
+	}
+	messages := publisher.Messages()
+	if published != 2 || len(messages) != 2 || messages[0].Accounts[0] != "200" || messages[1].Accounts[0] != "300" {
+		t.Fatalf("Expected a batch of deposits to 200 and 300 past the failing ones, got %d: %+v", published, messages)
+	}
+
+	relay.Drain()
+	if messages = publisher.Messages(); len(messages) != 3 || messages[2].Accounts[0] != "400" {
+		t.Errorf("Expected the deposit to 400 on the next drain, got %+v", messages)
+	}
+	if pending, _ := repo.PendingOutbox(0, 10); len(pending) != 3 {
+		t.Errorf("Expected the 3 failing messages to stay pending, got %d", len(pending))
+	}
+}
+
+func TestRelayPublishesAfterReset(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accountService := service.NewAccountService(repo, service.WithOutbox())
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(repo, publisher, DefaultRelayConfig())
+
+	accountService.Deposit(context.Background(), "100", 10)
+	if err := accountService.Reset(context.Background()); err != nil {
+		t.Fatalf("Expected no error resetting: %v", err)
+	}
+
+	if published, _ := relay.Drain(); published != 1 {
+		t.Errorf("Expected the deposit made before the reset to be published, got %d", published)
+	}
+}
@@ -0,0 +1,138 @@
+// Package ratelimit implements in-memory token buckets keyed by arbitrary
+// strings, such as client identities or account IDs.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// Rate is the number of tokens added per second. Zero disables the
+	// limiter.
+	Rate float64
+	// Burst is the bucket size: the most requests allowed at once.
+	Burst int
+	// IdleTimeout is how long an untouched bucket is kept. It is raised to
+	// the time a bucket takes to refill, so evicting a bucket never grants
+	// more than it would have held.
+	IdleTimeout time.Duration
+}
+
+// Decision is the outcome of a single Allow call.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed. It is
+	// zero when Allowed is true.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is safe for concurrent use.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	if cfg.Rate > 0 {
+		refill := time.Duration(float64(cfg.Burst) / cfg.Rate * float64(time.Second))
+		cfg.IdleTimeout = max(cfg.IdleTimeout, refill)
+	}
+	return &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Enabled reports whether the limiter has a positive rate. Allow always
+// succeeds otherwise.
+func (l *Limiter) Enabled() bool {
+	return l != nil && l.cfg.Rate > 0
+}
+
+// Allow takes a token from key's bucket if one is available.
+func (l *Limiter) Allow(key string) Decision {
+	return l.decide(key, true)
+}
+
+// Peek reports what Allow would decide for key without taking a token.
+func (l *Limiter) Peek(key string) Decision {
+	return l.decide(key, false)
+}
+
+func (l *Limiter) decide(key string, take bool) Decision {
+	if !l.Enabled() {
+		return Decision{Allowed: true}
+	}
+	now := l.now()
+	burst := float64(l.cfg.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.cfg.Rate)
+	b.last = now
+
+	d := Decision{Limit: l.cfg.Burst}
+	if b.tokens >= 1 {
+		if take {
+			b.tokens--
+		}
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(burst - b.tokens)
+	return d
+}
+
+// Len returns the number of buckets held.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// sweep evicts idle buckets, at most once per IdleTimeout.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.IdleTimeout {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.cfg.IdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// duration converts a number of tokens into the time needed to earn them.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.cfg.Rate * float64(time.Second))
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	now := time.Now()
+	l := NewLimiter(cfg)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_Burst(t *testing.T) {
+	l, _ := newTestLimiter(Config{Rate: 1, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if d := l.Allow("a"); !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("Expected request %d allowed with %d remaining, got %+v", i, 2-i, d)
+		}
+	}
+	d := l.Allow("a")
+	if d.Allowed {
+		t.Fatalf("Expected request beyond burst to be limited")
+	}
+	if d.RetryAfter != time.Second {
+		t.Errorf("Expected retry after 1s, got %s", d.RetryAfter)
+	}
+	if d.Reset != 3*time.Second {
+		t.Errorf("Expected reset in 3s, got %s", d.Reset)
+	}
+
+	if !l.Allow("b").Allowed {
+		t.Errorf("Expected other keys to have their own bucket")
+	}
+}
+
+func TestLimiter_Peek(t *testing.T) {
+	l, _ := newTestLimiter(Config{Rate: 1, Burst: 1})
+
+	for i := 0; i < 2; i++ {
+		if !l.Peek("a").Allowed {
+			t.Fatalf("Expected peek %d not to take the only token", i)
+		}
+	}
+	l.Allow("a")
+	if d := l.Peek("a"); d.Allowed || d.RetryAfter != time.Second {
+		t.Errorf("Expected peek to see the empty bucket, got %+v", d)
+	}
+}
+
+func TestLimiter_Refill(t *testing.T) {
+	l, now := newTestLimiter(Config{Rate: 2, Burst: 1})
+
+	l.Allow("a")
+	if l.Allow("a").Allowed {
+		t.Fatalf("Expected empty bucket")
+	}
+	*now = now.Add(500 * time.Millisecond)
+	if !l.Allow("a").Allowed {
+		t.Errorf("Expected a token after 500ms at 2/s")
+	}
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	l, now := newTestLimiter(Config{Rate: 1, Burst: 1, IdleTimeout: time.Minute})
+
+	l.Allow("a")
+	l.Allow("b")
+	*now = now.Add(2 * time.Minute)
+	l.Allow("c")
+
+	if l.Len() != 1 {
+		t.Errorf("Expected idle buckets to be evicted, have %d", l.Len())
+	}
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	var l *Limiter
+	if !l.Allow("a").Allowed || !NewLimiter(Config{}).Allow("a").Allowed {
+		t.Errorf("Expected disabled limiters to allow everything")
+	}
+}
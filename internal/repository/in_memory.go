@@ -1,23 +1,40 @@
 package repository
 
 import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
 )
 
 type InMemoryRepository struct {
-	accounts map[string]*domain.Account
-	mu       sync.RWMutex
+	accounts  map[string]*domain.Account
+	outbox    []domain.OutboxMessage
+	outboxSeq uint64
+	// journalSeq survives Reset so sequence numbers never repeat.
+	journal    []domain.JournalEntry
+	journalSeq uint64
+	ledger     map[string]*ledgerIndex
+	now        func() time.Time
+	mu         sync.RWMutex
 }
 
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
 		accounts: make(map[string]*domain.Account),
+		ledger:   make(map[string]*ledgerIndex),
+		now:      time.Now,
 	}
 }
 
-func (r *InMemoryRepository) FindByID(id string) (*domain.Account, error) {
+func (r *InMemoryRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -28,19 +45,314 @@ func (r *InMemoryRepository) FindByID(id string) (*domain.Account, error) {
 	return account, nil
 }
 
-func (r *InMemoryRepository) Upsert(account *domain.Account) (*domain.Account, error) {
+func (r *InMemoryRepository) Upsert(ctx context.Context, account *domain.Account) (*domain.Account, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.accounts[account.ID] = account
 
 	return account, nil
 }
 
-func (r *InMemoryRepository) Reset() error {
+// Count returns the number of accounts.
+func (r *InMemoryRepository) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.accounts)
+}
+
+func (r *InMemoryRepository) Reset(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Repository reset", "accounts", len(r.accounts), "pending_outbox", len(r.outbox))
+	r.clear()
+	return nil
+}
+
+// clear empties the repository, keeping sequence numbers. Committed outbox
+// messages describe events that really happened, so they stay pending for the
+// relay. The caller must hold r.mu.
+func (r *InMemoryRepository) clear() {
 	r.accounts = make(map[string]*domain.Account)
+	r.journal = nil
+	r.ledger = make(map[string]*ledgerIndex)
+}
+
+// Ping implements a readiness check. The in-memory store is always
+// reachable, but taking the lock catches a repository wedged by a stuck
+// transaction.
+func (r *InMemoryRepository) Ping(ctx context.Context) error {
+	locked := make(chan struct{})
+	go func() {
+		r.mu.RLock()
+		r.mu.RUnlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *InMemoryRepository) Transaction(ctx context.Context, fn func(tx domain.AccountTx) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The context is checked again once the lock is held, since waiting for
+	// it may have taken the request past its deadline.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := &inMemoryTx{
+		repo:   r,
+		writes: make(map[string]*domain.Account),
+	}
+	if err := fn(tx); err != nil {
+		slog.DebugContext(ctx, "Transaction rolled back", "error", err)
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		slog.DebugContext(ctx, "Transaction abandoned", "error", err)
+		return err
+	}
+
+	if tx.cleared {
+		slog.InfoContext(ctx, "Repository cleared", "accounts", len(r.accounts), "pending_outbox", len(r.outbox))
+		r.clear()
+	}
+	for id, account := range tx.writes {
+		r.accounts[id] = account
+	}
+	for _, msg := range tx.outbox {
+		r.outboxSeq++
+		msg.Seq = r.outboxSeq
+		r.outbox = append(r.outbox, msg)
+	}
+	for _, entry := range tx.journal {
+		r.appendJournal(entry)
+	}
+	slog.DebugContext(ctx, "Transaction committed", "accounts", len(tx.writes), "outbox", len(tx.outbox), "journal", len(tx.journal))
+	return nil
+}
+
+// appendJournal stamps entry with the next Seq and a commit time no earlier
+// than the previous entry's, so the journal is ordered by both, and indexes
+// its customer postings. The caller must hold r.mu.
+func (r *InMemoryRepository) appendJournal(entry domain.JournalEntry) {
+	r.journalSeq++
+	entry.Seq = r.journalSeq
+	entry.Time = r.now().UTC()
+	if n := len(r.journal); n > 0 && entry.Time.Before(r.journal[n-1].Time) {
+		entry.Time = r.journal[n-1].Time
+	}
+	r.journal = append(r.journal, entry)
+
+	seen := make(map[string]bool, len(entry.Lines))
+	for _, l := range entry.Lines {
+		if l.Account == domain.CashAccount || seen[l.Account] {
+			continue
+		}
+		seen[l.Account] = true
+		x, ok := r.ledger[l.Account]
+		if !ok {
+			x = &ledgerIndex{}
+			r.ledger[l.Account] = x
+		}
+		x.add(posting{seq: entry.Seq, time: entry.Time, net: entry.CustomerNet(l.Account)})
+	}
+}
+
+// BalanceAt returns a customer account's balance from the journal as of at.
+func (r *InMemoryRepository) BalanceAt(ctx context.Context, accountID string, at time.Time) (int, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, ok := r.ledger[accountID]
+	if !ok {
+		return 0, false, nil
+	}
+	balance, ok := x.balanceAt(at)
+	return balance, ok, nil
+}
+
+func (r *InMemoryRepository) Postings(ctx context.Context, accountID string, from, to time.Time, afterSeq uint64, limit int) ([]domain.Posting, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, ok := r.ledger[accountID]
+	if !ok {
+		return nil, nil
+	}
+	var postings []domain.Posting
+	for _, p := range x.postings[x.from(from, afterSeq):] {
+		if !p.time.Before(to) || len(postings) == limit {
+			break
+		}
+		entry := r.journalEntry(p.seq)
+		posting := domain.Posting{Seq: p.seq, Time: p.time, Type: entry.Type, Amount: p.net}
+		for _, l := range entry.Lines {
+			if l.Account != accountID && l.Account != domain.CashAccount {
+				posting.Counterparty = l.Account
+			}
+		}
+		postings = append(postings, posting)
+	}
+	return postings, nil
+}
+
+// journalEntry finds an entry by Seq. The caller must hold r.mu.
+func (r *InMemoryRepository) journalEntry(seq uint64) domain.JournalEntry {
+	i, _ := slices.BinarySearchFunc(r.journal, seq, func(e domain.JournalEntry, seq uint64) int {
+		return cmp.Compare(e.Seq, seq)
+	})
+	return r.journal[i]
+}
+
+func (r *InMemoryRepository) Journal(ctx context.Context, afterSeq uint64, limit int) ([]domain.JournalEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, _ := slices.BinarySearchFunc(r.journal, afterSeq+1, func(e domain.JournalEntry, seq uint64) int {
+		return cmp.Compare(e.Seq, seq)
+	})
+	n := min(limit, len(r.journal)-i)
+	return slices.Clone(r.journal[i : i+n]), nil
+}
+
+func (r *InMemoryRepository) ListAccounts(ctx context.Context) ([]domain.Account, uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]domain.Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, *account)
+	}
+	slices.SortFunc(accounts, func(a, b domain.Account) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return accounts, r.journalSeq, nil
+}
+
+func (r *InMemoryRepository) PendingOutbox(after uint64, limit int) ([]domain.OutboxMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start, _ := slices.BinarySearchFunc(r.outbox, after+1, func(msg domain.OutboxMessage, seq uint64) int {
+		return cmp.Compare(msg.Seq, seq)
+	})
+	end := start + min(limit, len(r.outbox)-start)
+	return slices.Clone(r.outbox[start:end]), nil
+}
+
+func (r *InMemoryRepository) AckOutbox(seqs ...uint64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	acked := make(map[uint64]struct{}, len(seqs))
+	for _, seq := range seqs {
+		acked[seq] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.outbox = slices.DeleteFunc(r.outbox, func(msg domain.OutboxMessage) bool {
+		_, ok := acked[msg.Seq]
+		return ok
+	})
+	return nil
+}
+
+// inMemoryTx stages writes until the transaction commits. Accounts are
+// copied in both directions so a rolled back transaction leaves no trace.
+type inMemoryTx struct {
+	repo    *InMemoryRepository
+	writes  map[string]*domain.Account
+	outbox  []domain.OutboxMessage
+	journal []domain.JournalEntry
+	cleared bool
+}
+
+func (tx *inMemoryTx) FindByID(id string) (*domain.Account, error) {
+	account, ok := tx.writes[id]
+	if !ok && !tx.cleared {
+		account, ok = tx.repo.accounts[id]
+	}
+	if !ok {
+		return nil, nil
+	}
+	found := *account
+	return &found, nil
+}
+
+func (tx *inMemoryTx) Upsert(account *domain.Account) (*domain.Account, error) {
+	stored := *account
+	tx.writes[account.ID] = &stored
+	return account, nil
+}
+
+func (tx *inMemoryTx) Accounts() ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0, len(tx.repo.accounts)+len(tx.writes))
+	if !tx.cleared {
+		for id, account := range tx.repo.accounts {
+			if _, written := tx.writes[id]; !written {
+				accounts = append(accounts, *account)
+			}
+		}
+	}
+	for _, account := range tx.writes {
+		accounts = append(accounts, *account)
+	}
+	slices.SortFunc(accounts, func(a, b domain.Account) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return accounts, nil
+}
+
+func (tx *inMemoryTx) AppendOutbox(messages ...domain.OutboxMessage) error {
+	tx.outbox = append(tx.outbox, messages...)
+	return nil
+}
+
+func (tx *inMemoryTx) PostJournal(entry domain.JournalEntry) error {
+	if !entry.Balanced() {
+		return domain.ErrUnbalancedEntry
+	}
+	entry.Lines = slices.Clone(entry.Lines)
+	tx.journal = append(tx.journal, entry)
+	return nil
+}
+
+func (tx *inMemoryTx) Clear() error {
+	tx.cleared = true
+	clear(tx.writes)
+	tx.outbox = nil
+	tx.journal = nil
 	return nil
 }
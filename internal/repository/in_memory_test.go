@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
@@ -9,7 +12,7 @@ import (
 func TestNonExistentAccount(t *testing.T) {
 	repo := NewInMemoryRepository()
 
-	account, err := repo.FindByID("non-existent")
+	account, err := repo.FindByID(context.Background(), "non-existent")
 
 	if err != nil {
 		t.Errorf("Expected error: %v", err)
@@ -23,7 +26,7 @@ func TestNonExistentAccount(t *testing.T) {
 func TestUpsertAccount(t *testing.T) {
 	repo := NewInMemoryRepository()
 
-	account, err := repo.Upsert(&domain.Account{
+	account, err := repo.Upsert(context.Background(), &domain.Account{
 		ID:      "123",
 		Balance: 100,
 	})
@@ -48,7 +51,7 @@ func TestUpsertAccount(t *testing.T) {
 func TestExistentAccount(t *testing.T) {
 	repo := NewInMemoryRepository()
 
-	_, err := repo.Upsert(&domain.Account{
+	_, err := repo.Upsert(context.Background(), &domain.Account{
 		ID:      "123",
 		Balance: 100,
 	})
@@ -57,7 +60,7 @@ func TestExistentAccount(t *testing.T) {
 		t.Errorf("Expected no error creating account: %v", err)
 	}
 
-	account, err := repo.FindByID("123")
+	account, err := repo.FindByID(context.Background(), "123")
 
 	if err != nil {
 		t.Errorf("Expected no error finding account: %v", err)
@@ -79,7 +82,7 @@ func TestExistentAccount(t *testing.T) {
 func TestUpdateAccount(t *testing.T) {
 	repo := NewInMemoryRepository()
 
-	account, err := repo.Upsert(&domain.Account{
+	account, err := repo.Upsert(context.Background(), &domain.Account{
 		ID:      "123",
 		Balance: 100,
 	})
@@ -89,7 +92,7 @@ func TestUpdateAccount(t *testing.T) {
 	}
 	account.Balance = 200
 
-	account, err = repo.Upsert(account)
+	account, err = repo.Upsert(context.Background(), account)
 
 	if err != nil {
 		t.Errorf("Expected no error updating account: %v", err)
@@ -111,7 +114,7 @@ func TestUpdateAccount(t *testing.T) {
 func TestReset(t *testing.T) {
 	repo := NewInMemoryRepository()
 
-	_, err := repo.Upsert(&domain.Account{
+	_, err := repo.Upsert(context.Background(), &domain.Account{
 		ID:      "123",
 		Balance: 100,
 	})
@@ -120,13 +123,13 @@ func TestReset(t *testing.T) {
 		t.Errorf("Expected no error creating account: %v", err)
 	}
 
-	err = repo.Reset()
+	err = repo.Reset(context.Background())
 
 	if err != nil {
 		t.Errorf("Expected no error resetting accounts: %v", err)
 	}
 
-	account, err := repo.FindByID("123")
+	account, err := repo.FindByID(context.Background(), "123")
 
 	if err != nil {
 		t.Errorf("Expected no error finding account: %v", err)
@@ -136,3 +139,205 @@ func TestReset(t *testing.T) {
 		t.Errorf("Expected nil account")
 	}
 }
+
+func TestTransactionRollback(t *testing.T) {
+	repo := NewInMemoryRepository()
+	repo.Upsert(context.Background(), &domain.Account{ID: "123", Balance: 100})
+
+	err := repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		account, _ := tx.FindByID("123")
+		account.Balance = 0
+		tx.Upsert(account)
+		tx.AppendOutbox(domain.OutboxMessage{ID: "msg"})
+		return errors.New("abort")
+	})
+
+	if err == nil {
+		t.Errorf("Expected transaction error")
+	}
+
+	account, _ := repo.FindByID(context.Background(), "123")
+	if account.Balance != 100 {
+		t.Errorf("Expected account balance to remain 100, got %d", account.Balance)
+	}
+
+	pending, _ := repo.PendingOutbox(0, 10)
+	if len(pending) != 0 {
+		t.Errorf("Expected empty outbox")
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	repo := NewInMemoryRepository()
+
+	err := repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		tx.Upsert(&domain.Account{ID: "123", Balance: 100})
+		return tx.AppendOutbox(domain.OutboxMessage{ID: "a"}, domain.OutboxMessage{ID: "b"})
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error committing: %v", err)
+	}
+
+	account, _ := repo.FindByID(context.Background(), "123")
+	if account == nil || account.Balance != 100 {
+		t.Errorf("Expected committed account")
+	}
+
+	pending, _ := repo.PendingOutbox(0, 10)
+	if len(pending) != 2 || pending[0].Seq != 1 || pending[1].Seq != 2 {
+		t.Errorf("Expected sequenced messages, got %+v", pending)
+	}
+
+	if after, _ := repo.PendingOutbox(1, 10); len(after) != 1 || after[0].ID != "b" {
+		t.Errorf("Expected only message b after seq 1, got %+v", after)
+	}
+
+	repo.AckOutbox(1)
+	pending, _ = repo.PendingOutbox(0, 10)
+	if len(pending) != 1 || pending[0].ID != "b" {
+		t.Errorf("Expected only message b pending, got %+v", pending)
+	}
+}
+
+func TestTransactionAccounts(t *testing.T) {
+	repo := NewInMemoryRepository()
+	repo.Upsert(context.Background(), &domain.Account{ID: "300", Balance: 3})
+	repo.Upsert(context.Background(), &domain.Account{ID: "100", Balance: 1})
+
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		tx.Upsert(&domain.Account{ID: "100", Balance: 10})
+		tx.Upsert(&domain.Account{ID: "200", Balance: 2})
+		accounts, _ := tx.Accounts()
+		want := []domain.Account{{ID: "100", Balance: 10}, {ID: "200", Balance: 2}, {ID: "300", Balance: 3}}
+		if !slices.Equal(accounts, want) {
+			t.Errorf("Expected %+v, got %+v", want, accounts)
+		}
+
+		tx.Clear()
+		if accounts, _ := tx.Accounts(); len(accounts) != 0 {
+			t.Errorf("Expected no accounts after a clear, got %+v", accounts)
+		}
+		return nil
+	})
+}
+
+func TestTransactionClear(t *testing.T) {
+	repo := NewInMemoryRepository()
+	repo.Upsert(context.Background(), &domain.Account{ID: "100", Balance: 10})
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		return tx.AppendOutbox(domain.OutboxMessage{ID: "old"})
+	})
+
+	abort := errors.New("abort")
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		tx.Clear()
+		return abort
+	})
+	if account, _ := repo.FindByID(context.Background(), "100"); account == nil {
+		t.Errorf("Expected a rolled back clear to keep account 100")
+	}
+
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		tx.Upsert(&domain.Account{ID: "300", Balance: 1})
+		tx.Clear()
+		if account, _ := tx.FindByID("100"); account != nil {
+			t.Errorf("Expected account 100 to be gone inside the transaction, got %+v", account)
+		}
+		tx.Upsert(&domain.Account{ID: "200", Balance: 5})
+		return tx.PostJournal(depositEntry("200", 5))
+	})
+
+	for id, want := range map[string]bool{"100": false, "200": true, "300": false} {
+		if account, _ := repo.FindByID(context.Background(), id); (account != nil) != want {
+			t.Errorf("Expected account %s to exist: %v, got %+v", id, want, account)
+		}
+	}
+	if pending, _ := repo.PendingOutbox(0, 10); len(pending) != 1 || pending[0].ID != "old" {
+		t.Errorf("Expected the undelivered message to survive the clear, got %+v", pending)
+	}
+	if entries, _ := repo.Journal(context.Background(), 0, 10); len(entries) != 1 || entries[0].Lines[1].Account != "200" {
+		t.Errorf("Expected only the journal entry posted after the clear, got %+v", entries)
+	}
+}
+
+func TestTransactionCanceledBeforeCommit(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		tx.Upsert(&domain.Account{ID: "123", Balance: 100})
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	account, _ := repo.FindByID(context.Background(), "123")
+	if account != nil {
+		t.Errorf("Expected no account to be committed")
+	}
+}
+
+func depositEntry(account string, amount int) domain.JournalEntry {
+	return domain.JournalEntry{Type: "deposit", Lines: []domain.JournalLine{
+		{Account: domain.CashAccount, Debit: amount},
+		{Account: account, Credit: amount},
+	}}
+}
+
+func TestPostJournal_RejectsUnbalanced(t *testing.T) {
+	repo := NewInMemoryRepository()
+
+	tests := map[string]domain.JournalEntry{
+		"empty":     {Type: "deposit"},
+		"unequal":   {Type: "deposit", Lines: []domain.JournalLine{{Account: domain.CashAccount, Debit: 10}, {Account: "100", Credit: 5}}},
+		"two-sided": {Type: "deposit", Lines: []domain.JournalLine{{Account: "100", Debit: 10, Credit: 10}}},
+		"negative":  {Type: "deposit", Lines: []domain.JournalLine{{Account: domain.CashAccount, Debit: -10}, {Account: "100", Credit: -10}}},
+	}
+	for name, entry := range tests {
+		err := repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+			return tx.PostJournal(entry)
+		})
+		if !errors.Is(err, domain.ErrUnbalancedEntry) {
+			t.Errorf("Expected ErrUnbalancedEntry for %s entry, got %v", name, err)
+		}
+	}
+	if entries, _ := repo.Journal(context.Background(), 0, 10); len(entries) != 0 {
+		t.Errorf("Expected an empty journal, got %+v", entries)
+	}
+}
+
+func TestJournal_Paging(t *testing.T) {
+	repo := NewInMemoryRepository()
+	for i := 0; i < 5; i++ {
+		repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+			return tx.PostJournal(depositEntry("100", 10))
+		})
+	}
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		tx.PostJournal(depositEntry("100", 10))
+		return errors.New("abort")
+	})
+
+	entries, _ := repo.Journal(context.Background(), 2, 2)
+	if len(entries) != 2 || entries[0].Seq != 3 || entries[1].Seq != 4 {
+		t.Errorf("Expected entries 3 and 4, got %+v", entries)
+	}
+	entries, _ = repo.Journal(context.Background(), 4, 10)
+	if len(entries) != 1 || entries[0].Seq != 5 {
+		t.Errorf("Expected only entry 5, got %+v", entries)
+	}
+
+	repo.Reset(context.Background())
+	repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+		return tx.PostJournal(depositEntry("100", 10))
+	})
+	_, head, _ := repo.ListAccounts(context.Background())
+	entries, _ = repo.Journal(context.Background(), 0, 10)
+	if len(entries) != 1 || entries[0].Seq != 6 || head != 6 {
+		t.Errorf("Expected only entry 6 after reset, got %+v at head %d", entries, head)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"sort"
+	"time"
+)
+
+// checkpointInterval is the number of postings between stored running
+// balances. A point-in-time lookup replays fewer than this many postings.
+const checkpointInterval = 256
+
+type posting struct {
+	seq  uint64
+	time time.Time
+	net  int
+}
+
+// ledgerIndex holds one customer account's journal postings in commit
+// order, with a checkpoint of the running balance every
+// checkpointInterval postings.
+type ledgerIndex struct {
+	postings    []posting
+	checkpoints []int
+	balance     int
+}
+
+func (x *ledgerIndex) add(p posting) {
+	x.postings = append(x.postings, p)
+	x.balance += p.net
+	if len(x.postings)%checkpointInterval == 0 {
+		x.checkpoints = append(x.checkpoints, x.balance)
+	}
+}
+
+// from returns the index of the first posting committed at or after from
+// with a seq above afterSeq.
+func (x *ledgerIndex) from(from time.Time, afterSeq uint64) int {
+	byTime := sort.Search(len(x.postings), func(i int) bool {
+		return !x.postings[i].time.Before(from)
+	})
+	bySeq := sort.Search(len(x.postings), func(i int) bool {
+		return x.postings[i].seq > afterSeq
+	})
+	return max(byTime, bySeq)
+}
+
+// balanceAt returns the balance after every posting at or before at. It
+// reports false when the account had no postings by then.
+func (x *ledgerIndex) balanceAt(at time.Time) (int, bool) {
+	n := sort.Search(len(x.postings), func(i int) bool {
+		return x.postings[i].time.After(at)
+	})
+	if n == 0 {
+		return 0, false
+	}
+	c := n / checkpointInterval
+	balance := 0
+	if c > 0 {
+		balance = x.checkpoints[c-1]
+	}
+	for _, p := range x.postings[c*checkpointInterval : n] {
+		balance += p.net
+	}
+	return balance, true
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func TestLedgerIndex_BalanceAt(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := &ledgerIndex{}
+	running := []int{}
+	balance := 0
+	// Enough postings to cross several checkpoints, two per instant.
+	for i := 0; i < 3*checkpointInterval+10; i++ {
+		net := i%7 - 2
+		balance += net
+		running = append(running, balance)
+		x.add(posting{seq: uint64(i + 1), time: start.Add(time.Duration(i/2) * time.Second), net: net})
+	}
+
+	if _, ok := x.balanceAt(start.Add(-time.Second)); ok {
+		t.Errorf("Expected no balance before the first posting")
+	}
+	for _, i := range []int{0, 1, checkpointInterval - 1, checkpointInterval, 2*checkpointInterval + 3, len(running) - 1} {
+		at := start.Add(time.Duration(i/2) * time.Second)
+		want := running[i|1]
+		if i|1 >= len(running) {
+			want = running[len(running)-1]
+		}
+		got, ok := x.balanceAt(at)
+		if !ok || got != want {
+			t.Errorf("Expected balance %d at posting %d, got %d", want, i, got)
+		}
+	}
+}
+
+func TestBalanceAt(t *testing.T) {
+	repo := NewInMemoryRepository()
+	clock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+	post := func(entry domain.JournalEntry) {
+		repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+			return tx.PostJournal(entry)
+		})
+	}
+
+	post(depositEntry("100", 50))
+	clock = clock.Add(time.Hour)
+	post(domain.JournalEntry{Type: "transfer", Lines: []domain.JournalLine{
+		{Account: "100", Debit: 20},
+		{Account: "200", Credit: 20},
+	}})
+	// A clock that steps back is clamped to the previous entry.
+	clock = clock.Add(-2 * time.Hour)
+	post(depositEntry("100", 5))
+
+	tests := []struct {
+		account string
+		at      time.Time
+		want    int
+		found   bool
+	}{
+		{"100", time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), 0, false},
+		{"100", time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC), 50, true},
+		{"100", time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC), 35, true},
+		{"200", time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC), 0, false},
+		{"200", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), 20, true},
+	}
+	for _, tt := range tests {
+		got, found, err := repo.BalanceAt(context.Background(), tt.account, tt.at)
+		if err != nil || got != tt.want || found != tt.found {
+			t.Errorf("Expected %s at %s to be %d (found %v), got %d (found %v, err %v)", tt.account, tt.at, tt.want, tt.found, got, found, err)
+		}
+	}
+}
+
+func TestPostings(t *testing.T) {
+	repo := NewInMemoryRepository()
+	clock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return clock }
+	post := func(entry domain.JournalEntry) {
+		repo.Transaction(context.Background(), func(tx domain.AccountTx) error {
+			return tx.PostJournal(entry)
+		})
+		clock = clock.Add(time.Hour)
+	}
+
+	post(depositEntry("100", 50))
+	post(domain.JournalEntry{Type: "transfer", Lines: []domain.JournalLine{
+		{Account: "100", Debit: 20},
+		{Account: "200", Credit: 20},
+	}})
+	post(depositEntry("200", 5))
+	post(depositEntry("100", 5))
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	postings, err := repo.Postings(context.Background(), "100", start.Add(time.Hour), start.Add(4*time.Hour), 0, 10)
+	want := []domain.Posting{
+		{Seq: 2, Time: start.Add(time.Hour), Type: "transfer", Counterparty: "200", Amount: -20},
+		{Seq: 4, Time: start.Add(3 * time.Hour), Type: "deposit", Amount: 5},
+	}
+	if err != nil || len(postings) != len(want) {
+		t.Fatalf("Expected %d postings, got %+v (err %v)", len(want), postings, err)
+	}
+	for i, w := range want {
+		if postings[i] != w {
+			t.Errorf("Expected posting %+v, got %+v", w, postings[i])
+		}
+	}
+
+	postings, _ = repo.Postings(context.Background(), "100", start, start.Add(3*time.Hour), 1, 1)
+	if len(postings) != 1 || postings[0].Seq != 2 {
+		t.Errorf("Expected only seq 2 after seq 1 and before the last deposit, got %+v", postings)
+	}
+	postings, _ = repo.Postings(context.Background(), "300", start, clock, 0, 10)
+	if len(postings) != 0 {
+		t.Errorf("Expected no postings for an unknown account, got %+v", postings)
+	}
+}
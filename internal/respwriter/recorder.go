@@ -0,0 +1,55 @@
+// Package respwriter provides the http.ResponseWriter wrapper shared by the
+// logging, metrics and tracing middleware.
+package respwriter
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// Recorder captures the status code and body size written through it.
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// Wrap returns w if it already is a Recorder, so stacked middleware share a
+// single wrapper, and a new Recorder around w otherwise.
+func Wrap(w http.ResponseWriter) *Recorder {
+	if rec, ok := w.(*Recorder); ok {
+		return rec
+	}
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *Recorder) Status() int {
+	return r.status
+}
+
+func (r *Recorder) Bytes() int {
+	return r.bytes
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack supports the WebSocket upgrade, which asserts http.Hijacker
+// directly instead of going through http.ResponseController.
+func (r *Recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
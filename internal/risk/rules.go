@@ -0,0 +1,190 @@
+// Package risk screens outgoing money before it moves. A pipeline of rules
+// looks at each withdraw and transfer, together with the origin account's
+// recent activity, and allows, denies or holds it for manual review.
+package risk
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// Decision is a rule's verdict on an event. Higher values are more severe.
+type Decision int
+
+const (
+	Allow Decision = iota
+	Review
+	Deny
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Review:
+		return "review"
+	case Deny:
+		return "deny"
+	default:
+		return "allow"
+	}
+}
+
+// ParseDecision parses "review" or "deny". Rules never act with "allow".
+func ParseDecision(s string) (Decision, error) {
+	switch s {
+	case "review":
+		return Review, nil
+	case "deny":
+		return Deny, nil
+	default:
+		return Allow, fmt.Errorf("invalid risk action %q: must be review or deny", s)
+	}
+}
+
+// Debit is a past withdraw or transfer out of an account.
+type Debit struct {
+	Type        string
+	Destination string
+	Amount      int
+	At          time.Time
+	// seq identifies a debit ProcessEvent reserved, so it can be released.
+	seq uint64
+}
+
+// Activity is what the engine remembers about an origin account.
+type Activity struct {
+	// Debits holds the most recent debits, oldest first.
+	Debits []Debit
+	// FirstPaid records when each destination first received a transfer.
+	FirstPaid map[string]time.Time
+}
+
+// Verdict is the outcome of evaluating an event.
+type Verdict struct {
+	Decision Decision
+	Rule     string
+	Reason   string
+}
+
+// Rule inspects an outgoing event. Rules must not modify activity.
+type Rule interface {
+	Name() string
+	Evaluate(event domain.EventRequest, activity Activity, now time.Time) Verdict
+}
+
+// Velocity acts when an account has made MaxCount debits within Window.
+type Velocity struct {
+	MaxCount int
+	Window   time.Duration
+	Action   Decision
+}
+
+func (r Velocity) Name() string { return "velocity" }
+
+func (r Velocity) Evaluate(event domain.EventRequest, activity Activity, now time.Time) Verdict {
+	since := now.Add(-r.Window)
+	count := 0
+	for _, d := range activity.Debits {
+		if d.At.After(since) {
+			count++
+		}
+	}
+	if count < r.MaxCount {
+		return Verdict{}
+	}
+	return Verdict{
+		Decision: r.Action,
+		Rule:     r.Name(),
+		Reason:   fmt.Sprintf("%d debits in the last %s", count, r.Window),
+	}
+}
+
+// Anomaly acts when an amount exceeds Multiplier times the account's
+// average debit. Accounts with fewer than MinHistory debits are not judged.
+type Anomaly struct {
+	Multiplier float64
+	MinHistory int
+	Action     Decision
+}
+
+func (r Anomaly) Name() string { return "anomaly" }
+
+func (r Anomaly) Evaluate(event domain.EventRequest, activity Activity, now time.Time) Verdict {
+	n := len(activity.Debits)
+	if n == 0 || n < r.MinHistory {
+		return Verdict{}
+	}
+	total := 0
+	for _, d := range activity.Debits {
+		total += d.Amount
+	}
+	mean := float64(total) / float64(n)
+	if float64(event.Amount) <= mean*r.Multiplier {
+		return Verdict{}
+	}
+	return Verdict{
+		Decision: r.Action,
+		Rule:     r.Name(),
+		Reason:   fmt.Sprintf("amount %d exceeds %.1fx the average of %.2f", event.Amount, r.Multiplier, mean),
+	}
+}
+
+// NewDestination acts on transfers above MinAmount to a destination the
+// origin first paid less than Cooldown ago, or has never paid.
+type NewDestination struct {
+	Cooldown  time.Duration
+	MinAmount int
+	Action    Decision
+}
+
+func (r NewDestination) Name() string { return "new_destination" }
+
+func (r NewDestination) Evaluate(event domain.EventRequest, activity Activity, now time.Time) Verdict {
+	if event.Type != "transfer" || event.Amount <= r.MinAmount {
+		return Verdict{}
+	}
+	first, ok := activity.FirstPaid[event.Destination]
+	if ok && now.Sub(first) >= r.Cooldown {
+		return Verdict{}
+	}
+	return Verdict{
+		Decision: r.Action,
+		Rule:     r.Name(),
+		Reason:   fmt.Sprintf("destination %s is within its %s cooldown", event.Destination, r.Cooldown),
+	}
+}
+
+// Blocklist denies events to or from any of Accounts.
+type Blocklist struct {
+	Accounts []string
+}
+
+func (r Blocklist) Name() string { return "blocklist" }
+
+func (r Blocklist) Evaluate(event domain.EventRequest, activity Activity, now time.Time) Verdict {
+	for _, id := range []string{event.Origin, event.Destination} {
+		if id != "" && slices.Contains(r.Accounts, id) {
+			return Verdict{
+				Decision: Deny,
+				Rule:     r.Name(),
+				Reason:   fmt.Sprintf("account %s is blocklisted", id),
+			}
+		}
+	}
+	return Verdict{}
+}
+
+// evaluate runs every rule and keeps the most severe verdict, preferring the
+// earliest rule on a tie.
+func evaluate(rules []Rule, event domain.EventRequest, activity Activity, now time.Time) Verdict {
+	var verdict Verdict
+	for _, rule := range rules {
+		v := rule.Evaluate(event, activity, now)
+		if v.Decision > verdict.Decision {
+			verdict = v
+		}
+	}
+	return verdict
+}
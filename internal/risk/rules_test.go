@@ -0,0 +1,98 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+var now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func debits(amounts ...int) []Debit {
+	out := make([]Debit, len(amounts))
+	for i, amount := range amounts {
+		out[i] = Debit{Type: "withdraw", Amount: amount, At: now.Add(-time.Duration(len(amounts)-i) * time.Minute)}
+	}
+	return out
+}
+
+func TestVelocity(t *testing.T) {
+	rule := Velocity{MaxCount: 3, Window: 5 * time.Minute, Action: Review}
+	event := domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 10}
+
+	if v := rule.Evaluate(event, Activity{Debits: debits(1, 1)}, now); v.Decision != Allow {
+		t.Errorf("Expected allow under the limit, got %s", v.Decision)
+	}
+	if v := rule.Evaluate(event, Activity{Debits: debits(1, 1, 1)}, now); v.Decision != Review {
+		t.Errorf("Expected review at the limit, got %s", v.Decision)
+	}
+	old := Activity{Debits: debits(1, 1, 1)}
+	if v := rule.Evaluate(event, old, now.Add(time.Hour)); v.Decision != Allow {
+		t.Errorf("Expected allow once debits leave the window, got %s", v.Decision)
+	}
+}
+
+func TestAnomaly(t *testing.T) {
+	rule := Anomaly{Multiplier: 5, MinHistory: 3, Action: Deny}
+	history := Activity{Debits: debits(10, 20, 30)}
+
+	if v := rule.Evaluate(domain.EventRequest{Amount: 100}, history, now); v.Decision != Allow {
+		t.Errorf("Expected allow at 5x the average, got %s", v.Decision)
+	}
+	if v := rule.Evaluate(domain.EventRequest{Amount: 101}, history, now); v.Decision != Deny {
+		t.Errorf("Expected deny above 5x the average, got %s", v.Decision)
+	}
+	short := Activity{Debits: debits(1, 1)}
+	if v := rule.Evaluate(domain.EventRequest{Amount: 1000}, short, now); v.Decision != Allow {
+		t.Errorf("Expected allow without enough history, got %s", v.Decision)
+	}
+}
+
+func TestNewDestination(t *testing.T) {
+	rule := NewDestination{Cooldown: time.Hour, MinAmount: 50, Action: Review}
+	activity := Activity{FirstPaid: map[string]time.Time{
+		"200": now.Add(-2 * time.Hour),
+		"300": now.Add(-time.Minute),
+	}}
+	tests := []struct {
+		destination string
+		amount      int
+		want        Decision
+	}{
+		{"200", 100, Allow},
+		{"300", 100, Review},
+		{"400", 100, Review},
+		{"400", 50, Allow},
+	}
+	for _, tt := range tests {
+		event := domain.EventRequest{Type: "transfer", Origin: "100", Destination: tt.destination, Amount: tt.amount}
+		if v := rule.Evaluate(event, activity, now); v.Decision != tt.want {
+			t.Errorf("Expected %s for %s/%d, got %s", tt.want, tt.destination, tt.amount, v.Decision)
+		}
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	rule := Blocklist{Accounts: []string{"666"}}
+
+	v := rule.Evaluate(domain.EventRequest{Type: "transfer", Origin: "100", Destination: "666", Amount: 1}, Activity{}, now)
+	if v.Decision != Deny || v.Rule != "blocklist" {
+		t.Errorf("Expected deny by blocklist, got %+v", v)
+	}
+	v = rule.Evaluate(domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 1}, Activity{}, now)
+	if v.Decision != Allow {
+		t.Errorf("Expected allow, got %s", v.Decision)
+	}
+}
+
+func TestEvaluate_MostSevereWins(t *testing.T) {
+	rules := []Rule{
+		Velocity{MaxCount: 1, Window: time.Hour, Action: Review},
+		Blocklist{Accounts: []string{"100"}},
+	}
+	v := evaluate(rules, domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 1}, Activity{Debits: debits(1)}, now)
+	if v.Decision != Deny || v.Rule != "blocklist" {
+		t.Errorf("Expected deny by blocklist, got %+v", v)
+	}
+}
@@ -0,0 +1,299 @@
+package risk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+type Config struct {
+	Rules []Rule
+	// HistorySize is the number of recent debits kept per account.
+	HistorySize int
+	// Audit, if set, records review decisions.
+	Audit domain.AuditLog
+}
+
+func DefaultConfig() Config {
+	return Config{HistorySize: 100}
+}
+
+// Service screens withdraws and transfers before passing them on to another
+// domain.EventService, and holds the ones that need review until an admin
+// approves or rejects them. It records the debits it allows, and learns the
+// rest, such as approved reviews, as a domain.EventListener, so it must be
+// registered on the service it wraps.
+type Service struct {
+	next domain.EventService
+	cfg  Config
+	now  func() time.Time
+
+	activity map[string]*Activity
+	reviews  map[string]*domain.Review
+	order    []string
+	deciding map[string]bool
+	seq      uint64
+	mu       sync.Mutex
+}
+
+// reservedKey marks the context of an event whose debit ProcessEvent has
+// already recorded, so OnEvent does not record it again.
+type reservedKey struct{}
+
+func NewService(next domain.EventService, cfg Config) *Service {
+	if cfg.HistorySize < 1 {
+		cfg.HistorySize = DefaultConfig().HistorySize
+	}
+	return &Service{
+		next:     next,
+		cfg:      cfg,
+		now:      time.Now,
+		activity: make(map[string]*Activity),
+		reviews:  make(map[string]*domain.Review),
+		deciding: make(map[string]bool),
+	}
+}
+
+func (s *Service) ProcessEvent(ctx context.Context, event domain.EventRequest) (*domain.EventResponse, error) {
+	if event.Type != "withdraw" && event.Type != "transfer" {
+		return s.next.ProcessEvent(ctx, event)
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	verdict := evaluate(s.cfg.Rules, event, s.snapshot(event.Origin), now)
+	var review *domain.Review
+	var reserved Debit
+	switch verdict.Decision {
+	case Allow:
+		// Recording the debit before releasing the lock means concurrent
+		// events from the same origin are evaluated against each other.
+		reserved = s.recordDebit(event, now)
+	case Review:
+		review = &domain.Review{
+			ID:        newID(),
+			Event:     event,
+			Rule:      verdict.Rule,
+			Reason:    verdict.Reason,
+			Status:    domain.ReviewPending,
+			CreatedAt: now.UTC(),
+		}
+		s.reviews[review.ID] = review
+		s.order = append(s.order, review.ID)
+		copied := *review
+		review = &copied
+	}
+	s.mu.Unlock()
+
+	switch verdict.Decision {
+	case Deny:
+		slog.WarnContext(ctx, "Event denied", "rule", verdict.Rule, "reason", verdict.Reason, "origin", event.Origin)
+		return nil, &domain.RiskError{Rule: verdict.Rule, Reason: verdict.Reason}
+	case Review:
+		slog.InfoContext(ctx, "Event held for review", "review_id", review.ID, "rule", verdict.Rule, "origin", event.Origin)
+		return nil, &domain.RiskError{Rule: verdict.Rule, Reason: verdict.Reason, Review: review}
+	}
+	resp, err := s.next.ProcessEvent(context.WithValue(ctx, reservedKey{}, true), event)
+	if err != nil {
+		s.mu.Lock()
+		s.releaseDebit(event.Origin, reserved)
+		s.mu.Unlock()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OnEvent implements domain.EventListener, recording debits for later
+// evaluations. Debits that went through ProcessEvent were recorded when they
+// were allowed.
+func (s *Service) OnEvent(ctx context.Context, event domain.EventRequest, resp *domain.EventResponse) {
+	if event.Type != "withdraw" && event.Type != "transfer" {
+		return
+	}
+	if reserved, _ := ctx.Value(reservedKey{}).(bool); reserved {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recordDebit(event, s.now())
+}
+
+// recordDebit adds event to its origin's activity. The caller must hold
+// s.mu.
+func (s *Service) recordDebit(event domain.EventRequest, now time.Time) Debit {
+	a, ok := s.activity[event.Origin]
+	if !ok {
+		a = &Activity{FirstPaid: make(map[string]time.Time)}
+		s.activity[event.Origin] = a
+	}
+	s.seq++
+	d := Debit{
+		Type:        event.Type,
+		Destination: event.Destination,
+		Amount:      event.Amount,
+		At:          now,
+		seq:         s.seq,
+	}
+	a.Debits = append(a.Debits, d)
+	if n := len(a.Debits) - s.cfg.HistorySize; n > 0 {
+		a.Debits = slices.Delete(a.Debits, 0, n)
+	}
+	if event.Type == "transfer" {
+		if _, seen := a.FirstPaid[event.Destination]; !seen {
+			a.FirstPaid[event.Destination] = now
+		}
+	}
+	return d
+}
+
+// releaseDebit undoes recordDebit for a debit that failed to apply. A
+// destination is forgotten again if no remaining debit paid it. The caller
+// must hold s.mu.
+func (s *Service) releaseDebit(origin string, d Debit) {
+	a, ok := s.activity[origin]
+	if !ok {
+		return
+	}
+	a.Debits = slices.DeleteFunc(a.Debits, func(other Debit) bool { return other.seq == d.seq })
+	if d.Type != "transfer" || !a.FirstPaid[d.Destination].Equal(d.At) {
+		return
+	}
+	paid := slices.ContainsFunc(a.Debits, func(other Debit) bool {
+		return other.Type == "transfer" && other.Destination == d.Destination
+	})
+	if !paid {
+		delete(a.FirstPaid, d.Destination)
+	}
+}
+
+// snapshot copies an account's activity so rules can read it freely. The
+// caller must hold s.mu.
+func (s *Service) snapshot(accountID string) Activity {
+	a, ok := s.activity[accountID]
+	if !ok {
+		return Activity{}
+	}
+	return Activity{
+		Debits:    slices.Clone(a.Debits),
+		FirstPaid: maps.Clone(a.FirstPaid),
+	}
+}
+
+func (s *Service) Reviews(ctx context.Context, status domain.ReviewStatus) ([]domain.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reviews := make([]domain.Review, 0, len(s.order))
+	for _, id := range s.order {
+		r := s.reviews[id]
+		if status == "" || r.Status == status {
+			reviews = append(reviews, *r)
+		}
+	}
+	return reviews, nil
+}
+
+// Approve applies the held event through the wrapped service. An event that
+// fails to apply marks the review failed, except when ctx is done, which
+// leaves it pending so it can be approved again.
+func (s *Service) Approve(ctx context.Context, id, reviewer string) (*domain.Review, error) {
+	s.mu.Lock()
+	review, err := s.pending(id)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.deciding[id] = true
+	event := review.Event
+	s.mu.Unlock()
+
+	resp, err := s.next.ProcessEvent(ctx, event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deciding, id)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	before := *review
+	if err != nil {
+		review.Error = err.Error()
+		s.decide(review, domain.ReviewFailed, reviewer, "")
+		slog.WarnContext(ctx, "Approved event failed", "review_id", id, "error", err)
+	} else {
+		review.Result = resp.Clone()
+		s.decide(review, domain.ReviewApproved, reviewer, "")
+		slog.InfoContext(ctx, "Review approved", "review_id", id, "reviewer", reviewer)
+	}
+	decided := *review
+	// The decision already stands, so a failed audit write is logged rather
+	// than returned.
+	if err := s.record(ctx, domain.AuditReviewApprove, before, decided); err != nil {
+		slog.ErrorContext(ctx, "Error recording review approval in audit log", "review_id", id, "error", err)
+	}
+	return &decided, nil
+}
+
+func (s *Service) Reject(ctx context.Context, id, reviewer, note string) (*domain.Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	review, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *review
+	after := before
+	s.decide(&after, domain.ReviewRejected, reviewer, note)
+	if err := s.record(ctx, domain.AuditReviewReject, before, after); err != nil {
+		return nil, err
+	}
+	*review = after
+	slog.InfoContext(ctx, "Review rejected", "review_id", id, "reviewer", reviewer)
+	return &after, nil
+}
+
+// pending returns a review that is still awaiting a decision. The caller
+// must hold s.mu.
+func (s *Service) pending(id string) (*domain.Review, error) {
+	review, ok := s.reviews[id]
+	if !ok {
+		return nil, domain.ErrReviewNotFound
+	}
+	if review.Status != domain.ReviewPending || s.deciding[id] {
+		return nil, domain.ErrReviewDecided
+	}
+	return review, nil
+}
+
+func (s *Service) decide(review *domain.Review, status domain.ReviewStatus, reviewer, note string) {
+	decidedAt := s.now().UTC()
+	review.Status = status
+	review.DecidedAt = &decidedAt
+	review.DecidedBy = reviewer
+	review.Note = note
+}
+
+func (s *Service) record(ctx context.Context, action string, before, after domain.Review) error {
+	if s.cfg.Audit == nil {
+		return nil
+	}
+	return s.cfg.Audit.Record(ctx, action, before.ID, before, after)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "rev_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,207 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+func newTestService(t *testing.T, rules ...Rule) (*Service, *service.AccountService) {
+	t.Helper()
+	accounts := service.NewAccountService(repository.NewInMemoryRepository())
+	events := service.NewEventService(accounts)
+	cfg := DefaultConfig()
+	cfg.Rules = rules
+	s := NewService(events, cfg)
+	s.now = func() time.Time { return now }
+	events.AddListener(s)
+	return s, accounts
+}
+
+func TestProcessEvent_Denied(t *testing.T) {
+	s, accounts := newTestService(t, Blocklist{Accounts: []string{"666"}})
+	accounts.Deposit(context.Background(), "100", 50)
+
+	_, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "transfer", Origin: "100", Destination: "666", Amount: 10})
+	if !errors.Is(err, domain.ErrRiskDenied) {
+		t.Fatalf("Expected ErrRiskDenied, got %v", err)
+	}
+	if balance, _ := accounts.GetBalance(context.Background(), "100"); balance != 50 {
+		t.Errorf("Expected balance 50, got %d", balance)
+	}
+}
+
+func TestProcessEvent_DepositsSkipRules(t *testing.T) {
+	s, _ := newTestService(t, Blocklist{Accounts: []string{"100"}})
+
+	if _, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 10}); err != nil {
+		t.Errorf("Expected no error depositing, got %v", err)
+	}
+}
+
+func TestProcessEvent_RecordsActivity(t *testing.T) {
+	s, accounts := newTestService(t, Velocity{MaxCount: 2, Window: time.Minute, Action: Deny})
+	accounts.Deposit(context.Background(), "100", 50)
+	withdraw := domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 1}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.ProcessEvent(context.Background(), withdraw); err != nil {
+			t.Fatalf("Expected withdraw %d to pass, got %v", i, err)
+		}
+	}
+	if _, err := s.ProcessEvent(context.Background(), withdraw); !errors.Is(err, domain.ErrRiskDenied) {
+		t.Errorf("Expected ErrRiskDenied on the third withdraw, got %v", err)
+	}
+}
+
+// gatedEvents holds every event until release is closed, reporting each
+// one on entered.
+type gatedEvents struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (g gatedEvents) ProcessEvent(ctx context.Context, event domain.EventRequest) (*domain.EventResponse, error) {
+	g.entered <- struct{}{}
+	<-g.release
+	return &domain.EventResponse{}, nil
+}
+
+func TestProcessEvent_ConcurrentDebits(t *testing.T) {
+	next := gatedEvents{entered: make(chan struct{}), release: make(chan struct{})}
+	cfg := DefaultConfig()
+	cfg.Rules = []Rule{Velocity{MaxCount: 2, Window: time.Minute, Action: Deny}}
+	s := NewService(next, cfg)
+	s.now = func() time.Time { return now }
+
+	const n = 10
+	denied := make(chan error, n)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 1})
+			if err != nil {
+				denied <- err
+			}
+			errs <- err
+		}()
+	}
+
+	// Every withdrawal is either denied or waiting in next before any
+	// completes.
+	entered := 0
+	for i := 0; i < n; i++ {
+		select {
+		case <-next.entered:
+			entered++
+		case err := <-denied:
+			if !errors.Is(err, domain.ErrRiskDenied) {
+				t.Errorf("Expected ErrRiskDenied, got %v", err)
+			}
+		}
+	}
+	close(next.release)
+	for i := 0; i < n; i++ {
+		<-errs
+	}
+	if entered != 2 {
+		t.Errorf("Expected 2 withdrawals to pass, got %d", entered)
+	}
+}
+
+func TestProcessEvent_FailedDebitReleased(t *testing.T) {
+	s, accounts := newTestService(t, Velocity{MaxCount: 1, Window: time.Minute, Action: Deny})
+	accounts.Deposit(context.Background(), "100", 50)
+
+	_, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 80})
+	if !errors.Is(err, domain.ErrInsufficientFunds) {
+		t.Fatalf("Expected ErrInsufficientFunds, got %v", err)
+	}
+	if _, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 10}); err != nil {
+		t.Errorf("Expected the failed withdraw not to count, got %v", err)
+	}
+}
+
+func TestReview_Approve(t *testing.T) {
+	s, accounts := newTestService(t, NewDestination{Cooldown: time.Hour, Action: Review})
+	accounts.Deposit(context.Background(), "100", 50)
+	transfer := domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 10}
+
+	_, err := s.ProcessEvent(context.Background(), transfer)
+	var riskErr *domain.RiskError
+	if !errors.As(err, &riskErr) || riskErr.Review == nil {
+		t.Fatalf("Expected a pending review, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrPendingReview) {
+		t.Errorf("Expected ErrPendingReview, got %v", err)
+	}
+	pending, _ := s.Reviews(context.Background(), domain.ReviewPending)
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pending review, got %d", len(pending))
+	}
+
+	review, err := s.Approve(context.Background(), riskErr.Review.ID, "admin")
+	if err != nil {
+		t.Fatalf("Expected no error approving, got %v", err)
+	}
+	if review.Status != domain.ReviewApproved || review.DecidedBy != "admin" {
+		t.Errorf("Expected approved by admin, got %+v", review)
+	}
+	if review.Result == nil || review.Result.Origin.Balance != 40 {
+		t.Errorf("Expected origin balance 40, got %+v", review.Result)
+	}
+	if _, err := s.Approve(context.Background(), review.ID, "admin"); !errors.Is(err, domain.ErrReviewDecided) {
+		t.Errorf("Expected ErrReviewDecided approving twice, got %v", err)
+	}
+
+	// The approved transfer still counts as the first payment to 300.
+	if _, err := s.ProcessEvent(context.Background(), transfer); !errors.Is(err, domain.ErrPendingReview) {
+		t.Errorf("Expected ErrPendingReview within the cooldown, got %v", err)
+	}
+}
+
+func TestReview_ApproveFailed(t *testing.T) {
+	s, accounts := newTestService(t, NewDestination{Cooldown: time.Hour, Action: Review})
+	accounts.Deposit(context.Background(), "100", 50)
+
+	_, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 10})
+	var riskErr *domain.RiskError
+	errors.As(err, &riskErr)
+	accounts.Withdraw(context.Background(), "100", 50)
+
+	review, err := s.Approve(context.Background(), riskErr.Review.ID, "admin")
+	if err != nil {
+		t.Fatalf("Expected no error approving, got %v", err)
+	}
+	if review.Status != domain.ReviewFailed || review.Error == "" {
+		t.Errorf("Expected a failed review, got %+v", review)
+	}
+}
+
+func TestReview_Reject(t *testing.T) {
+	s, accounts := newTestService(t, NewDestination{Cooldown: time.Hour, Action: Review})
+	accounts.Deposit(context.Background(), "100", 50)
+
+	_, err := s.ProcessEvent(context.Background(), domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 10})
+	var riskErr *domain.RiskError
+	errors.As(err, &riskErr)
+
+	review, err := s.Reject(context.Background(), riskErr.Review.ID, "admin", "looks like fraud")
+	if err != nil {
+		t.Fatalf("Expected no error rejecting, got %v", err)
+	}
+	if review.Status != domain.ReviewRejected || review.Note != "looks like fraud" {
+		t.Errorf("Expected a rejected review with note, got %+v", review)
+	}
+	if balance, _ := accounts.GetBalance(context.Background(), "100"); balance != 50 {
+		t.Errorf("Expected balance 50, got %d", balance)
+	}
+	if _, err := s.Reject(context.Background(), "rev_missing", "admin", ""); !errors.Is(err, domain.ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound, got %v", err)
+	}
+}
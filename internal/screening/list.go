@@ -0,0 +1,214 @@
+// Package screening checks accounts against a sanctions list kept in a
+// local file.
+package screening
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// Entry is a listed account.
+type Entry struct {
+	AccountID string `json:"account_id"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// List implements domain.Screener from a CSV or JSON file, picked by the
+// ".json" extension. CSV rows are "account_id,reason", with an optional
+// header row and "#" comments. JSON files hold an array of entries.
+//
+// The file is checked for changes at most once per ReloadInterval, on the
+// next Screen call. A file that fails to load leaves the previous list in
+// place.
+type List struct {
+	path           string
+	reloadInterval time.Duration
+	audit          domain.AuditLog
+	now            func() time.Time
+
+	entries   map[string]Entry
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+	mu        sync.RWMutex
+}
+
+type Option func(*List)
+
+// WithAuditLog records every screening hit.
+func WithAuditLog(audit domain.AuditLog) Option {
+	return func(l *List) {
+		l.audit = audit
+	}
+}
+
+func LoadList(path string, reloadInterval time.Duration, opts ...Option) (*List, error) {
+	l := &List{path: path, reloadInterval: reloadInterval, now: time.Now}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *List) Screen(ctx context.Context, event domain.EventRequest) error {
+	l.maybeReload()
+
+	entry, ok := l.lookup(event.Origin, event.Destination)
+	if !ok {
+		return nil
+	}
+	slog.WarnContext(ctx, "Screening hit",
+		"audit", true,
+		"event_type", event.Type,
+		"account_id", entry.AccountID,
+		"reason", entry.Reason,
+	)
+	if l.audit != nil {
+		if err := l.audit.Record(ctx, domain.AuditScreeningBlock, entry.AccountID, nil, event); err != nil {
+			slog.ErrorContext(ctx, "Error recording screening hit in audit log", "error", err)
+		}
+	}
+	return &domain.ScreeningError{AccountID: entry.AccountID, Reason: entry.Reason}
+}
+
+func (l *List) lookup(ids ...string) (Entry, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, id := range ids {
+		if entry, ok := l.entries[id]; ok && id != "" {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Len returns the number of listed accounts.
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entries)
+}
+
+func (l *List) maybeReload() {
+	if l.reloadInterval <= 0 {
+		return
+	}
+	now := l.now()
+	l.mu.Lock()
+	due := now.Sub(l.lastCheck) >= l.reloadInterval
+	if due {
+		l.lastCheck = now
+	}
+	l.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := l.load(); err != nil {
+		slog.Error("Error reloading screening list, keeping previous entries", "path", l.path, "error", err)
+	}
+}
+
+// load reads the list file unless it is unchanged since the last load.
+func (l *List) load() error {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return err
+	}
+	l.mu.RLock()
+	unchanged := l.entries != nil && info.ModTime().Equal(l.modTime) && info.Size() == l.size
+	l.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var entries []Entry
+	if strings.EqualFold(filepath.Ext(l.path), ".json") {
+		entries, err = parseJSON(f)
+	} else {
+		entries, err = parseCSV(f)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", l.path, err)
+	}
+
+	byID := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		byID[e.AccountID] = e
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = byID
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+	l.lastCheck = l.now()
+	slog.Info("Screening list loaded", "path", l.path, "entries", len(byID))
+	return nil
+}
+
+func parseJSON(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if e.AccountID == "" {
+			return nil, fmt.Errorf("entry %d: missing account_id", i)
+		}
+	}
+	return entries, nil
+}
+
+func parseCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var entries []Entry
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSpace(record[0])
+		if first && id == "account_id" {
+			continue
+		}
+		if id == "" {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: missing account_id", line)
+		}
+		entry := Entry{AccountID: id}
+		if len(record) > 1 {
+			entry.Reason = strings.TrimSpace(record[1])
+		}
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,103 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadList_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.csv")
+	writeFile(t, path, "account_id,reason\n# comment\n666, OFAC SDN\n777\n", time.Now())
+
+	l, err := LoadList(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+	if l.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", l.Len())
+	}
+
+	err = l.Screen(context.Background(), domain.EventRequest{Type: "transfer", Origin: "100", Destination: "666", Amount: 1})
+	var screeningErr *domain.ScreeningError
+	if !errors.As(err, &screeningErr) || screeningErr.AccountID != "666" || screeningErr.Reason != "OFAC SDN" {
+		t.Errorf("Expected 666 to be blocked for OFAC SDN, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrAccountBlocked) {
+		t.Errorf("Expected ErrAccountBlocked, got %v", err)
+	}
+	if err := l.Screen(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 1}); err != nil {
+		t.Errorf("Expected 100 to pass, got %v", err)
+	}
+}
+
+func TestLoadList_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.json")
+	writeFile(t, path, `[{"account_id":"666","reason":"fraud"}]`, time.Now())
+
+	l, err := LoadList(path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+	err = l.Screen(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "666", Amount: 1})
+	if !errors.Is(err, domain.ErrAccountBlocked) {
+		t.Errorf("Expected ErrAccountBlocked, got %v", err)
+	}
+}
+
+func TestLoadList_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"missing.json": `[{"reason":"no id"}]`,
+		"unknown.json": `[{"account_id":"1","extra":true}]`,
+		"empty.csv":    "666\n,reason\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, content, time.Now())
+		if _, err := LoadList(path, 0); err == nil {
+			t.Errorf("Expected an error loading %s", name)
+		}
+	}
+}
+
+func TestList_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.csv")
+	loaded := time.Now().Add(-time.Hour)
+	writeFile(t, path, "666\n", loaded)
+
+	l, err := LoadList(path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Now()
+	l.now = func() time.Time { return clock }
+	event := domain.EventRequest{Type: "withdraw", Origin: "777", Amount: 1}
+
+	writeFile(t, path, "666\n777\n", loaded.Add(time.Second))
+	clock = clock.Add(time.Minute)
+	if err := l.Screen(context.Background(), event); !errors.Is(err, domain.ErrAccountBlocked) {
+		t.Errorf("Expected the changed list to be reloaded, got %v", err)
+	}
+
+	writeFile(t, path, "not,valid,\"csv\n", loaded.Add(2*time.Second))
+	clock = clock.Add(time.Minute)
+	if err := l.Screen(context.Background(), event); !errors.Is(err, domain.ErrAccountBlocked) {
+		t.Errorf("Expected the previous list to be kept after a bad reload, got %v", err)
+	}
+}
@@ -1,34 +1,119 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
 )
 
 type AccountService struct {
-	repo domain.AccountRepository
+	repo   domain.AccountRepository
+	outbox bool
+	audit  domain.AuditLog
+	// screener checks imported events; /event screens in EventService.
+	screener domain.Screener
 }
 
-func NewAccountService(repo domain.AccountRepository) *AccountService {
-	return &AccountService{
+type AccountServiceOption func(*AccountService)
+
+// WithOutbox records an outbox message in the same transaction as every
+// balance change, for an outbox relay to publish.
+func WithOutbox() AccountServiceOption {
+	return func(s *AccountService) {
+		s.outbox = true
+	}
+}
+
+// WithAuditLog records resets and freeze changes. Freeze changes are
+// recorded inside their transaction, so a change whose entry cannot be
+// written is rolled back. Resets are recorded after they commit.
+func WithAuditLog(audit domain.AuditLog) AccountServiceOption {
+	return func(s *AccountService) {
+		s.audit = audit
+	}
+}
+
+// WithImportScreener checks every imported event against screener, as
+// WithScreener does for /event. Blocked events fail their line.
+func WithImportScreener(screener domain.Screener) AccountServiceOption {
+	return func(s *AccountService) {
+		s.screener = screener
+	}
+}
+
+func NewAccountService(repo domain.AccountRepository, opts ...AccountServiceOption) *AccountService {
+	s := &AccountService{
 		repo: repo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *AccountService) GetBalance(accountID string) (int, error) {
-	account, err := s.repo.FindByID(accountID)
+func (s *AccountService) GetBalance(ctx context.Context, accountID string) (int, error) {
+	account, err := s.repo.FindByID(ctx, accountID)
 	if err != nil {
 		return 0, err
 	}
 	if account == nil {
-		return 0, fmt.Errorf("Account not found")
+		return 0, domain.ErrAccountNotFound
 	}
 	return account.Balance, nil
 }
 
-func (s *AccountService) Deposit(accountID string, amount int) (*domain.Account, error) {
-	account, err := s.repo.FindByID(accountID)
+func (s *AccountService) Deposit(ctx context.Context, accountID string, amount int) (*domain.Account, error) {
+	var account *domain.Account
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		account, err = s.deposit(tx, accountID, amount)
+		return err
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "Deposit failed", "account_id", accountID, "amount", amount, "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Deposit applied", "account_id", accountID, "amount", amount, "balance", account.Balance)
+	return account, nil
+}
+
+func (s *AccountService) Withdraw(ctx context.Context, accountID string, amount int) (*domain.Account, error) {
+	var account *domain.Account
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		account, err = s.withdraw(tx, accountID, amount)
+		return err
+	})
+	if err != nil {
+		slog.InfoContext(ctx, "Withdraw rejected", "account_id", accountID, "amount", amount, "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Withdraw applied", "account_id", accountID, "amount", amount, "balance", account.Balance)
+	return account, nil
+}
+
+func (s *AccountService) Transfer(ctx context.Context, originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
+	var originAccount, destinationAccount *domain.Account
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		originAccount, destinationAccount, err = s.transfer(tx, originID, destinationID, amount)
+		return err
+	})
+	if err != nil {
+		slog.InfoContext(ctx, "Transfer rejected", "origin", originID, "destination", destinationID, "amount", amount, "error", err)
+		return nil, nil, err
+	}
+	slog.DebugContext(ctx, "Transfer applied", "origin", originID, "destination", destinationID, "amount", amount)
+	return originAccount, destinationAccount, nil
+}
+
+func (s *AccountService) deposit(tx domain.AccountTx, accountID string, amount int) (*domain.Account, error) {
+	account, err := tx.FindByID(accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -38,39 +123,81 @@ func (s *AccountService) Deposit(accountID string, amount int) (*domain.Account,
 			Balance: 0,
 		}
 	}
+	if account.Frozen {
+		return nil, domain.ErrAccountFrozen
+	}
 	account.Balance += amount
-	return s.repo.Upsert(account)
+	if account, err = tx.Upsert(account); err != nil {
+		return nil, err
+	}
+	err = postJournal(tx, "deposit",
+		domain.JournalLine{Account: domain.CashAccount, Debit: amount},
+		domain.JournalLine{Account: accountID, Credit: amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = s.recordOutbox(tx, domain.EventRequest{
+		Type:        "deposit",
+		Destination: accountID,
+		Amount:      amount,
+	}, &domain.EventResponse{Destination: account})
+	return account, err
 }
 
-func (s *AccountService) Withdraw(accountID string, amount int) (*domain.Account, error) {
-	account, err := s.repo.FindByID(accountID)
+func (s *AccountService) withdraw(tx domain.AccountTx, accountID string, amount int) (*domain.Account, error) {
+	account, err := tx.FindByID(accountID)
 	if err != nil {
 		return nil, err
 	}
 	if account == nil {
-		return nil, fmt.Errorf("Account not found")
+		return nil, domain.ErrAccountNotFound
+	}
+	if account.Frozen {
+		return nil, domain.ErrAccountFrozen
 	}
 	if account.Balance < amount {
-		return nil, fmt.Errorf("Insufficient funds")
+		return nil, domain.ErrInsufficientFunds
 	}
 	account.Balance -= amount
-	return s.repo.Upsert(account)
+	if account, err = tx.Upsert(account); err != nil {
+		return nil, err
+	}
+	err = postJournal(tx, "withdraw",
+		domain.JournalLine{Account: accountID, Debit: amount},
+		domain.JournalLine{Account: domain.CashAccount, Credit: amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = s.recordOutbox(tx, domain.EventRequest{
+		Type:   "withdraw",
+		Origin: accountID,
+		Amount: amount,
+	}, &domain.EventResponse{Origin: account})
+	return account, err
 }
 
-func (s *AccountService) Transfer(originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
-	originAccount, err := s.repo.FindByID(originID)
+func (s *AccountService) transfer(tx domain.AccountTx, originID, destinationID string, amount int) (*domain.Account, *domain.Account, error) {
+	originAccount, err := tx.FindByID(originID)
 	if err != nil {
 		return nil, nil, err
 	}
 	if originAccount == nil {
-		return nil, nil, fmt.Errorf("Origin account not found")
+		return nil, nil, domain.ErrOriginAccountNotFound
+	}
+	if originAccount.Frozen {
+		return nil, nil, domain.ErrAccountFrozen
 	}
 	if originAccount.Balance < amount {
-		return nil, nil, fmt.Errorf("Insufficient funds")
+		return nil, nil, domain.ErrInsufficientFunds
 	}
 	originAccount.Balance -= amount
+	if originAccount, err = tx.Upsert(originAccount); err != nil {
+		return nil, nil, err
+	}
 
-	destinationAccount, err := s.repo.FindByID(destinationID)
+	destinationAccount, err := tx.FindByID(destinationID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -80,19 +207,148 @@ func (s *AccountService) Transfer(originID, destinationID string, amount int) (*
 			Balance: 0,
 		}
 	}
+	if destinationAccount.Frozen {
+		return nil, nil, domain.ErrAccountFrozen
+	}
 	destinationAccount.Balance += amount
-
-	originAccount, err = s.repo.Upsert(originAccount)
-	if err != nil {
+	if destinationAccount, err = tx.Upsert(destinationAccount); err != nil {
 		return nil, nil, err
 	}
-	destinationAccount, err = s.repo.Upsert(destinationAccount)
+	// FindByID returns copies, so a transfer to the same account must report
+	// the copy holding both the debit and the credit.
+	if originID == destinationID {
+		originAccount = destinationAccount
+	}
+	err = postJournal(tx, "transfer",
+		domain.JournalLine{Account: originID, Debit: amount},
+		domain.JournalLine{Account: destinationID, Credit: amount},
+	)
 	if err != nil {
 		return nil, nil, err
 	}
-	return originAccount, destinationAccount, nil
+
+	err = s.recordOutbox(tx, domain.EventRequest{
+		Type:        "transfer",
+		Origin:      originID,
+		Destination: destinationID,
+		Amount:      amount,
+	}, &domain.EventResponse{Origin: originAccount, Destination: destinationAccount})
+	return originAccount, destinationAccount, err
+}
+
+// Reset removes every account. With an audit log, the accounts removed are
+// summarised in the same transaction and recorded once it commits, so an
+// abandoned reset leaves no entry.
+func (s *AccountService) Reset(ctx context.Context) error {
+	if s.audit == nil {
+		return s.repo.Reset(ctx)
+	}
+	var before stateRecord
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		if before, err = replacedState(tx); err != nil {
+			return err
+		}
+		return tx.Clear()
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, domain.AuditReset, "", before, nil); err != nil {
+		slog.ErrorContext(ctx, "Error recording reset in audit log", "error", err)
+		return err
+	}
+	return nil
+}
+
+// stateRecord is what the audit log keeps of the accounts a reset or
+// restore replaced. The accounts themselves are summarised by a checksum,
+// so an entry stays small however many there were.
+type stateRecord struct {
+	Accounts int `json:"accounts"`
+	Total    int `json:"total_balance"`
+	// Checksum is the SHA-256 of the accounts as a JSON array sorted by ID.
+	Checksum string `json:"checksum"`
+}
+
+func replacedState(tx domain.AccountTx) (stateRecord, error) {
+	accounts, err := tx.Accounts()
+	if err != nil {
+		return stateRecord{}, err
+	}
+	data, err := json.Marshal(accounts)
+	if err != nil {
+		return stateRecord{}, err
+	}
+	sum := sha256.Sum256(data)
+	record := stateRecord{Accounts: len(accounts), Checksum: "sha256:" + hex.EncodeToString(sum[:])}
+	for _, a := range accounts {
+		record.Total += a.Balance
+	}
+	return record, nil
+}
+
+// SetFrozen freezes or unfreezes an existing account.
+func (s *AccountService) SetFrozen(ctx context.Context, accountID string, frozen bool) (*domain.Account, error) {
+	var account *domain.Account
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		account, err = tx.FindByID(accountID)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return domain.ErrAccountNotFound
+		}
+		before := *account
+		account.Frozen = frozen
+		if account, err = tx.Upsert(account); err != nil {
+			return err
+		}
+		if s.audit == nil {
+			return nil
+		}
+		action := domain.AuditUnfreeze
+		if frozen {
+			action = domain.AuditFreeze
+		}
+		return s.audit.Record(ctx, action, accountID, before, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Account freeze changed", "account_id", accountID, "frozen", frozen)
+	return account, nil
+}
+
+// postJournal records a balance change as a double-entry journal entry.
+// Zero amounts move no money and post nothing.
+func postJournal(tx domain.AccountTx, eventType string, lines ...domain.JournalLine) error {
+	if lines[0].Debit == 0 && lines[0].Credit == 0 {
+		return nil
+	}
+	return tx.PostJournal(domain.JournalEntry{
+		Type:  eventType,
+		Lines: lines,
+	})
 }
 
-func (s *AccountService) Reset() error {
-	return s.repo.Reset()
+func (s *AccountService) recordOutbox(tx domain.AccountTx, event domain.EventRequest, resp *domain.EventResponse) error {
+	if !s.outbox {
+		return nil
+	}
+	accounts := []string{}
+	if event.Origin != "" {
+		accounts = append(accounts, event.Origin)
+	}
+	if event.Destination != "" && event.Destination != event.Origin {
+		accounts = append(accounts, event.Destination)
+	}
+	return tx.AppendOutbox(domain.OutboxMessage{
+		ID:        newID("msg"),
+		Accounts:  accounts,
+		Event:     event,
+		Result:    resp.Clone(),
+		CreatedAt: time.Now().UTC(),
+	})
 }
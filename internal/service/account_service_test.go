@@ -1,8 +1,17 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
 	"testing"
 
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
 	"github.com/thihxm/ebanx-home-assignment/internal/repository"
 )
 
@@ -10,7 +19,7 @@ func TestGetNonExistentAccount(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.GetBalance("123")
+	_, err := service.GetBalance(context.Background(), "123")
 	if err == nil {
 		t.Errorf("Expected error getting balance")
 	}
@@ -20,12 +29,12 @@ func TestGetBalance(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	balance, err := service.GetBalance("123")
+	balance, err := service.GetBalance(context.Background(), "123")
 	if err != nil {
 		t.Errorf("Expected no error getting balance: %v", err)
 	}
@@ -38,7 +47,7 @@ func TestDeposit(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	account, err := service.Deposit("123", 100)
+	account, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
@@ -57,7 +66,7 @@ func TestWithdrawNonExistentAccount(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Withdraw("123", 100)
+	_, err := service.Withdraw(context.Background(), "123", 100)
 	if err == nil {
 		t.Errorf("Expected error withdrawing")
 	}
@@ -67,12 +76,12 @@ func TestWithdraw(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	account, err := service.Withdraw("123", 100)
+	account, err := service.Withdraw(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error withdrawing: %v", err)
 	}
@@ -91,12 +100,12 @@ func TestWithdrawInsufficientFunds(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, err = service.Withdraw("123", 200)
+	_, err = service.Withdraw(context.Background(), "123", 200)
 	if err == nil {
 		t.Errorf("Expected error withdrawing")
 	}
@@ -106,7 +115,7 @@ func TestTransferNonExistentAccounts(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, _, err := service.Transfer("123", "456", 100)
+	_, _, err := service.Transfer(context.Background(), "123", "456", 100)
 	if err == nil {
 		t.Errorf("Expected error transferring")
 	}
@@ -116,12 +125,12 @@ func TestTransferNonExistentOriginAccount(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("456", 100)
+	_, err := service.Deposit(context.Background(), "456", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, _, err = service.Transfer("123", "456", 100)
+	_, _, err = service.Transfer(context.Background(), "123", "456", 100)
 	if err == nil {
 		t.Errorf("Expected error transferring")
 	}
@@ -131,12 +140,12 @@ func TestTransferNonExistentDestinationAccount(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, destinationAccount, err := service.Transfer("123", "456", 100)
+	_, destinationAccount, err := service.Transfer(context.Background(), "123", "456", 100)
 	if err != nil {
 		t.Errorf("Expected no error transferring: %v", err)
 	}
@@ -156,17 +165,17 @@ func TestTransfer(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, err = service.Deposit("456", 100)
+	_, err = service.Deposit(context.Background(), "456", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	originAccount, destinationAccount, err := service.Transfer("123", "456", 100)
+	originAccount, destinationAccount, err := service.Transfer(context.Background(), "123", "456", 100)
 	if err != nil {
 		t.Errorf("Expected no error transferring: %v", err)
 	}
@@ -189,17 +198,17 @@ func TestTransferInsufficientFunds(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, err = service.Deposit("456", 0)
+	_, err = service.Deposit(context.Background(), "456", 0)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	_, _, err = service.Transfer("123", "456", 200)
+	_, _, err = service.Transfer(context.Background(), "123", "456", 200)
 	if err == nil {
 		t.Errorf("Expected error transferring")
 	}
@@ -209,18 +218,373 @@ func TestReset(t *testing.T) {
 	repo := repository.NewInMemoryRepository()
 	service := NewAccountService(repo)
 
-	_, err := service.Deposit("123", 100)
+	_, err := service.Deposit(context.Background(), "123", 100)
 	if err != nil {
 		t.Errorf("Expected no error depositing: %v", err)
 	}
 
-	err = service.Reset()
+	err = service.Reset(context.Background())
 	if err != nil {
 		t.Errorf("Expected no error resetting: %v", err)
 	}
 
-	_, err = service.GetBalance("123")
+	_, err = service.GetBalance(context.Background(), "123")
 	if err == nil {
 		t.Errorf("Expected error getting balance")
 	}
 }
+
+func TestDeposit_CanceledContext(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	service := NewAccountService(repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.Deposit(ctx, "123", 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := service.GetBalance(context.Background(), "123"); err == nil {
+		t.Errorf("Expected the deposit not to be applied")
+	}
+}
+
+func TestFrozenAccountRejectsChanges(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	service := NewAccountService(repo)
+
+	service.Deposit(context.Background(), "100", 50)
+	service.Deposit(context.Background(), "200", 50)
+	if _, err := service.SetFrozen(context.Background(), "100", true); err != nil {
+		t.Fatalf("Expected no error freezing: %v", err)
+	}
+
+	if _, err := service.Deposit(context.Background(), "100", 10); !errors.Is(err, domain.ErrAccountFrozen) {
+		t.Errorf("Expected ErrAccountFrozen on deposit, got %v", err)
+	}
+	if _, _, err := service.Transfer(context.Background(), "200", "100", 10); !errors.Is(err, domain.ErrAccountFrozen) {
+		t.Errorf("Expected ErrAccountFrozen on transfer in, got %v", err)
+	}
+	if balance, _ := service.GetBalance(context.Background(), "200"); balance != 50 {
+		t.Errorf("Expected rejected transfer to leave origin at 50, got %d", balance)
+	}
+
+	service.SetFrozen(context.Background(), "100", false)
+	if _, err := service.Withdraw(context.Background(), "100", 10); err != nil {
+		t.Errorf("Expected no error after unfreezing: %v", err)
+	}
+}
+
+func TestSetFrozen_NotFound(t *testing.T) {
+	service := NewAccountService(repository.NewInMemoryRepository())
+
+	if _, err := service.SetFrozen(context.Background(), "100", true); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+type mockAuditLog struct {
+	actions []string
+	befores []any
+	err     error
+}
+
+func (m *mockAuditLog) Record(ctx context.Context, action, target string, before, after any) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.actions = append(m.actions, action+" "+target)
+	m.befores = append(m.befores, before)
+	return nil
+}
+
+func (m *mockAuditLog) Entries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return nil, nil
+}
+
+func TestSetFrozen_Audited(t *testing.T) {
+	audit := &mockAuditLog{}
+	s := NewAccountService(repository.NewInMemoryRepository(), WithAuditLog(audit))
+	s.Deposit(context.Background(), "100", 10)
+
+	s.SetFrozen(context.Background(), "100", true)
+	s.Reset(context.Background())
+
+	if len(audit.actions) != 2 || audit.actions[0] != "account.freeze 100" || audit.actions[1] != "reset " {
+		t.Errorf("Expected freeze and reset to be recorded, got %v", audit.actions)
+	}
+}
+
+func TestReset_AuditsRemovedAccounts(t *testing.T) {
+	audit := &mockAuditLog{}
+	s := NewAccountService(repository.NewInMemoryRepository(), WithAuditLog(audit))
+	s.Deposit(context.Background(), "300", 7)
+	s.Deposit(context.Background(), "100", 10)
+
+	if err := s.Reset(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := json.Marshal([]domain.Account{{ID: "100", Balance: 10}, {ID: "300", Balance: 7}})
+	sum := sha256.Sum256(data)
+	want := stateRecord{Accounts: 2, Total: 17, Checksum: "sha256:" + hex.EncodeToString(sum[:])}
+	if before, ok := audit.befores[0].(stateRecord); !ok || before != want {
+		t.Errorf("Expected %+v recorded before the reset, got %+v", want, audit.befores[0])
+	}
+	if _, err := s.GetBalance(context.Background(), "100"); err != domain.ErrAccountNotFound {
+		t.Errorf("Expected the accounts to be removed, got %v", err)
+	}
+}
+
+func TestReset_AbandonedNotAudited(t *testing.T) {
+	audit := &mockAuditLog{}
+	s := NewAccountService(repository.NewInMemoryRepository(), WithAuditLog(audit))
+	s.Deposit(context.Background(), "100", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Reset(ctx); err == nil {
+		t.Fatal("Expected an error resetting with a cancelled context")
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("Expected an abandoned reset not to be recorded, got %v", audit.actions)
+	}
+	if balance, _ := s.GetBalance(context.Background(), "100"); balance != 10 {
+		t.Errorf("Expected the reset not to apply, got balance %d", balance)
+	}
+}
+
+func TestSetFrozen_AuditFailureRollsBack(t *testing.T) {
+	s := NewAccountService(repository.NewInMemoryRepository(), WithAuditLog(&mockAuditLog{err: errors.New("disk full")}))
+	s.Deposit(context.Background(), "100", 10)
+
+	if _, err := s.SetFrozen(context.Background(), "100", true); err == nil {
+		t.Fatal("Expected an error when the audit log fails")
+	}
+	if _, err := s.Withdraw(context.Background(), "100", 5); err != nil {
+		t.Errorf("Expected the account to stay unfrozen, got %v", err)
+	}
+}
+
+// modelOp is one randomly generated call on AccountService.
+type modelOp struct {
+	kind                string
+	origin, destination string
+	amount              int
+}
+
+func (op modelOp) String() string {
+	return fmt.Sprintf("%s %s->%s %d", op.kind, op.origin, op.destination, op.amount)
+}
+
+// accountModel is the reference AccountService is checked against: plain
+// maps with the rules written out once more, without transactions.
+type accountModel struct {
+	balances map[string]int
+	frozen   map[string]bool
+}
+
+// apply returns what the service should answer for op.
+func (m *accountModel) apply(op modelOp) (origin, destination *domain.Account, err error) {
+	account := func(id string, balance int) *domain.Account {
+		return &domain.Account{ID: id, Balance: balance, Frozen: m.frozen[id]}
+	}
+	switch op.kind {
+	case "deposit":
+		if m.frozen[op.destination] {
+			return nil, nil, domain.ErrAccountFrozen
+		}
+		m.balances[op.destination] += op.amount
+		return nil, account(op.destination, m.balances[op.destination]), nil
+	case "withdraw":
+		balance, ok := m.balances[op.origin]
+		switch {
+		case !ok:
+			return nil, nil, domain.ErrAccountNotFound
+		case m.frozen[op.origin]:
+			return nil, nil, domain.ErrAccountFrozen
+		case balance < op.amount:
+			return nil, nil, domain.ErrInsufficientFunds
+		}
+		m.balances[op.origin] -= op.amount
+		return account(op.origin, m.balances[op.origin]), nil, nil
+	case "transfer":
+		balance, ok := m.balances[op.origin]
+		switch {
+		case !ok:
+			return nil, nil, domain.ErrOriginAccountNotFound
+		case m.frozen[op.origin]:
+			return nil, nil, domain.ErrAccountFrozen
+		case balance < op.amount:
+			return nil, nil, domain.ErrInsufficientFunds
+		case m.frozen[op.destination]:
+			return nil, nil, domain.ErrAccountFrozen
+		}
+		m.balances[op.origin] -= op.amount
+		m.balances[op.destination] += op.amount
+		return account(op.origin, m.balances[op.origin]), account(op.destination, m.balances[op.destination]), nil
+	default:
+		if _, ok := m.balances[op.destination]; !ok {
+			return nil, nil, domain.ErrAccountNotFound
+		}
+		m.frozen[op.destination] = op.kind == "freeze"
+		return nil, account(op.destination, m.balances[op.destination]), nil
+	}
+}
+
+var modelAccounts = []string{"100", "200", "300", "400"}
+
+// randomOps draws from few accounts and small amounts, so that overdrafts,
+// missing accounts and self-transfers come up often.
+func randomOps(r *rand.Rand, n int, kinds ...string) []modelOp {
+	ops := make([]modelOp, n)
+	for i := range ops {
+		ops[i] = modelOp{
+			kind:        kinds[r.IntN(len(kinds))],
+			origin:      modelAccounts[r.IntN(len(modelAccounts))],
+			destination: modelAccounts[r.IntN(len(modelAccounts))],
+			amount:      1 + r.IntN(50),
+		}
+	}
+	return ops
+}
+
+func applyOp(ctx context.Context, s *AccountService, op modelOp) (origin, destination *domain.Account, err error) {
+	switch op.kind {
+	case "deposit":
+		destination, err = s.Deposit(ctx, op.destination, op.amount)
+	case "withdraw":
+		origin, err = s.Withdraw(ctx, op.origin, op.amount)
+	case "transfer":
+		origin, destination, err = s.Transfer(ctx, op.origin, op.destination, op.amount)
+	default:
+		destination, err = s.SetFrozen(ctx, op.destination, op.kind == "freeze")
+	}
+	return origin, destination, err
+}
+
+func sameAccount(a, b *domain.Account) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// checkBooks asserts the invariants that must hold between any two calls:
+// no negative balances, money only created by deposits and destroyed by
+// withdrawals, and a journal that balances and agrees with every account.
+func checkBooks(t *testing.T, repo *repository.InMemoryRepository, wantTotal int) {
+	t.Helper()
+	accounts, _, err := repo.ListAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error listing accounts: %v", err)
+	}
+	total := 0
+	for _, a := range accounts {
+		if a.Balance < 0 {
+			t.Errorf("Expected no negative balances, got %+v", a)
+		}
+		total += a.Balance
+	}
+	if total != wantTotal {
+		t.Errorf("Expected %d in all accounts, got %d", wantTotal, total)
+	}
+	tb, err := NewLedgerService(repo).TrialBalance(context.Background())
+	if err != nil || !tb.OK() {
+		t.Errorf("Expected a balanced ledger, got %+v (err %v)", tb, err)
+	}
+}
+
+func TestAccountService_MatchesModel(t *testing.T) {
+	ctx := context.Background()
+	for seed := range uint64(20) {
+		r := rand.New(rand.NewPCG(seed, 1))
+		repo := repository.NewInMemoryRepository()
+		s := NewAccountService(repo)
+		model := &accountModel{balances: make(map[string]int), frozen: make(map[string]bool)}
+		total := 0
+
+		for i, op := range randomOps(r, 300, "deposit", "deposit", "withdraw", "transfer", "transfer", "freeze", "unfreeze") {
+			wantOrigin, wantDestination, wantErr := model.apply(op)
+			origin, destination, err := applyOp(ctx, s, op)
+
+			if !errors.Is(err, wantErr) || (wantErr == nil && err != nil) {
+				t.Fatalf("seed %d, op %d (%v): expected error %v, got %v", seed, i, op, wantErr, err)
+			}
+			if !sameAccount(origin, wantOrigin) || !sameAccount(destination, wantDestination) {
+				t.Fatalf("seed %d, op %d (%v): expected %+v %+v, got %+v %+v",
+					seed, i, op, wantOrigin, wantDestination, origin, destination)
+			}
+			if err == nil && op.kind == "deposit" {
+				total += op.amount
+			}
+			if err == nil && op.kind == "withdraw" {
+				total -= op.amount
+			}
+			for _, id := range modelAccounts {
+				want, exists := model.balances[id]
+				got, err := s.GetBalance(ctx, id)
+				if exists != (err == nil) || got != want {
+					t.Fatalf("seed %d, op %d (%v): expected account %s at %d (exists %v), got %d (err %v)",
+						seed, i, op, id, want, exists, got, err)
+				}
+			}
+			checkBooks(t, repo, total)
+		}
+	}
+}
+
+func TestAccountService_ConcurrentInvariants(t *testing.T) {
+	const workers = 8
+	ctx := context.Background()
+	repo := repository.NewInMemoryRepository()
+	s := NewAccountService(repo)
+
+	// Each worker records the net effect of the calls that succeeded. The
+	// order they interleave in is unknown, but the sums must add up.
+	nets := make([]map[string]int, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		nets[w] = make(map[string]int)
+		r := rand.New(rand.NewPCG(uint64(w), 2))
+		wg.Go(func() {
+			for _, op := range randomOps(r, 500, "deposit", "withdraw", "transfer") {
+				origin, _, err := applyOp(ctx, s, op)
+				switch {
+				case err == nil:
+				case errors.Is(err, domain.ErrAccountNotFound),
+					errors.Is(err, domain.ErrOriginAccountNotFound),
+					errors.Is(err, domain.ErrInsufficientFunds):
+					continue
+				default:
+					t.Errorf("Expected only not-found or insufficient-funds errors, got %v", err)
+					continue
+				}
+				if origin != nil && origin.Balance < 0 {
+					t.Errorf("Expected no negative balances, got %+v", origin)
+				}
+				if op.kind != "deposit" {
+					nets[w][op.origin] -= op.amount
+				}
+				if op.kind != "withdraw" {
+					nets[w][op.destination] += op.amount
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	want := make(map[string]int)
+	total := 0
+	for _, net := range nets {
+		for id, delta := range net {
+			want[id] += delta
+			total += delta
+		}
+	}
+	for id, balance := range want {
+		if got, _ := s.GetBalance(ctx, id); got != balance {
+			t.Errorf("Expected account %s at %d, got %d", id, balance, got)
+		}
+	}
+	checkBooks(t, repo, total)
+}
@@ -1,25 +1,76 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
 )
 
 type EventService struct {
 	accountService domain.AccountService
+	screener       domain.Screener
+	listeners      []domain.EventListener
+	mu             sync.RWMutex
 }
 
-func NewEventService(accountService domain.AccountService) *EventService {
-	return &EventService{
+type EventServiceOption func(*EventService)
+
+// WithScreener rejects every event involving an account the screener
+// blocks, before any balance changes.
+func WithScreener(screener domain.Screener) EventServiceOption {
+	return func(s *EventService) {
+		s.screener = screener
+	}
+}
+
+func NewEventService(accountService domain.AccountService, opts ...EventServiceOption) *EventService {
+	s := &EventService{
 		accountService: accountService,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddListener registers a listener that is notified of every successfully
+// processed event.
+func (s *EventService) AddListener(listener domain.EventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *EventService) ProcessEvent(ctx context.Context, event domain.EventRequest) (*domain.EventResponse, error) {
+	if s.screener != nil {
+		if err := s.screener.Screen(ctx, event); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := s.apply(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, event, resp)
+	return resp, nil
+}
+
+func (s *EventService) notify(ctx context.Context, event domain.EventRequest, resp *domain.EventResponse) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, listener := range s.listeners {
+		listener.OnEvent(ctx, event, resp)
+	}
 }
 
-func (s *EventService) ProcessEvent(event domain.EventRequest) (*domain.EventResponse, error) {
+func (s *EventService) apply(ctx context.Context, event domain.EventRequest) (*domain.EventResponse, error) {
 	switch event.Type {
 	case "deposit":
-		account, err := s.accountService.Deposit(event.Destination, event.Amount)
+		account, err := s.accountService.Deposit(ctx, event.Destination, event.Amount)
 		if err != nil {
 			return nil, err
 		}
@@ -27,7 +78,7 @@ func (s *EventService) ProcessEvent(event domain.EventRequest) (*domain.EventRes
 			Destination: account,
 		}, nil
 	case "withdraw":
-		account, err := s.accountService.Withdraw(event.Origin, event.Amount)
+		account, err := s.accountService.Withdraw(ctx, event.Origin, event.Amount)
 		if err != nil {
 			return nil, err
 		}
@@ -35,7 +86,7 @@ func (s *EventService) ProcessEvent(event domain.EventRequest) (*domain.EventRes
 			Origin: account,
 		}, nil
 	case "transfer":
-		originAccount, destinationAccount, err := s.accountService.Transfer(event.Origin, event.Destination, event.Amount)
+		originAccount, destinationAccount, err := s.accountService.Transfer(ctx, event.Origin, event.Destination, event.Amount)
 		if err != nil {
 			return nil, err
 		}
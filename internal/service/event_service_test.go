@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/thihxm/ebanx-home-assignment/internal/domain"
@@ -12,7 +14,7 @@ func TestHandleDepositEvent(t *testing.T) {
 	accountService := NewAccountService(repo)
 	eventService := NewEventService(accountService)
 
-	_, err := eventService.ProcessEvent(domain.EventRequest{
+	_, err := eventService.ProcessEvent(context.Background(), domain.EventRequest{
 		Type:   "deposit",
 		Origin: "123",
 		Amount: 100,
@@ -27,9 +29,9 @@ func TestHandleWithdrawEvent(t *testing.T) {
 	accountService := NewAccountService(repo)
 	eventService := NewEventService(accountService)
 
-	accountService.Deposit("123", 100)
+	accountService.Deposit(context.Background(), "123", 100)
 
-	_, err := eventService.ProcessEvent(domain.EventRequest{
+	_, err := eventService.ProcessEvent(context.Background(), domain.EventRequest{
 		Type:   "withdraw",
 		Origin: "123",
 		Amount: 100,
@@ -44,10 +46,10 @@ func TestHandleTransferEvent(t *testing.T) {
 	accountService := NewAccountService(repo)
 	eventService := NewEventService(accountService)
 
-	accountService.Deposit("123", 100)
-	accountService.Deposit("456", 0)
+	accountService.Deposit(context.Background(), "123", 100)
+	accountService.Deposit(context.Background(), "456", 0)
 
-	_, err := eventService.ProcessEvent(domain.EventRequest{
+	_, err := eventService.ProcessEvent(context.Background(), domain.EventRequest{
 		Type:        "transfer",
 		Origin:      "123",
 		Destination: "456",
@@ -57,3 +59,35 @@ func TestHandleTransferEvent(t *testing.T) {
 		t.Errorf("Expected no error transferring: %v", err)
 	}
 }
+
+type blockingScreener struct {
+	blocked string
+}
+
+func (s blockingScreener) Screen(ctx context.Context, event domain.EventRequest) error {
+	if event.Origin == s.blocked || event.Destination == s.blocked {
+		return &domain.ScreeningError{AccountID: s.blocked}
+	}
+	return nil
+}
+
+func TestProcessEvent_Screened(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accountService := NewAccountService(repo)
+	eventService := NewEventService(accountService, WithScreener(blockingScreener{blocked: "666"}))
+
+	accountService.Deposit(context.Background(), "123", 100)
+
+	_, err := eventService.ProcessEvent(context.Background(), domain.EventRequest{
+		Type:        "transfer",
+		Origin:      "123",
+		Destination: "666",
+		Amount:      50,
+	})
+	if !errors.Is(err, domain.ErrAccountBlocked) {
+		t.Fatalf("Expected ErrAccountBlocked, got %v", err)
+	}
+	if balance, _ := accountService.GetBalance(context.Background(), "123"); balance != 100 {
+		t.Errorf("Expected balance 100, got %d", balance)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+var (
+	// errDryRun rolls back a dry-run import once every record has applied.
+	errDryRun = errors.New("dry run")
+	// errImportRejected rolls back an import with failing records.
+	errImportRejected = errors.New("import rejected")
+)
+
+// ImportEvents applies events in order, exactly as /event would but without
+// risk rules or listeners. Events involving an account the import screener
+// blocks are reported as line errors. Outbox messages are still recorded.
+func (s *AccountService) ImportEvents(ctx context.Context, events []domain.ImportedEvent, dryRun bool) (*domain.ImportReport, error) {
+	return s.runImport(ctx, domain.ImportEvents, len(events), dryRun, func(apply applyFunc) {
+		for _, e := range events {
+			ok := apply(e.Line, func(tx domain.AccountTx) error {
+				return s.importEvent(ctx, tx, e.Event)
+			})
+			if !ok {
+				return
+			}
+		}
+	})
+}
+
+func (s *AccountService) importEvent(ctx context.Context, tx domain.AccountTx, event domain.EventRequest) error {
+	if s.screener != nil {
+		if err := s.screener.Screen(ctx, event); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch event.Type {
+	case "deposit":
+		_, err = s.deposit(tx, event.Destination, event.Amount)
+	case "withdraw":
+		_, err = s.withdraw(tx, event.Origin, event.Amount)
+	case "transfer":
+		_, _, err = s.transfer(tx, event.Origin, event.Destination, event.Amount)
+	default:
+		err = errors.New("Unknown event type " + event.Type)
+	}
+	return err
+}
+
+// ImportAccounts sets each account's balance and freeze. The difference from
+// the current balance is posted to the journal against @cash, so the books
+// still balance.
+func (s *AccountService) ImportAccounts(ctx context.Context, accounts []domain.ImportedAccount, dryRun bool) (*domain.ImportReport, error) {
+	return s.runImport(ctx, domain.ImportAccounts, len(accounts), dryRun, func(apply applyFunc) {
+		for _, a := range accounts {
+			ok := apply(a.Line, func(tx domain.AccountTx) error {
+				return s.restore(tx, a.Account)
+			})
+			if !ok {
+				return
+			}
+		}
+	})
+}
+
+func (s *AccountService) restore(tx domain.AccountTx, account domain.Account) error {
+	existing, err := tx.FindByID(account.ID)
+	if err != nil {
+		return err
+	}
+	delta := account.Balance
+	if existing != nil {
+		delta -= existing.Balance
+	}
+	if _, err := tx.Upsert(&account); err != nil {
+		return err
+	}
+	if delta >= 0 {
+		return postJournal(tx, "import",
+			domain.JournalLine{Account: domain.CashAccount, Debit: delta},
+			domain.JournalLine{Account: account.ID, Credit: delta},
+		)
+	}
+	return postJournal(tx, "import",
+		domain.JournalLine{Account: account.ID, Debit: -delta},
+		domain.JournalLine{Account: domain.CashAccount, Credit: -delta},
+	)
+}
+
+// applyFunc applies the record on line, reporting whether the import should
+// go on. A failing record is reported in the result and none of its writes
+// are kept, so the records after it run as if it were not in the file.
+type applyFunc func(line int, fn func(tx domain.AccountTx) error) bool
+
+// runImport runs records in one transaction, handing it an applyFunc that
+// stages each record on its own stagedTx. Records stop after
+// domain.MaxImportErrors failures. Failing records are reported in the
+// result rather than returned, which is kept for storage and context errors.
+func (s *AccountService) runImport(ctx context.Context, kind string, records int, dryRun bool, run func(apply applyFunc)) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{Kind: kind, DryRun: dryRun, Records: records}
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		report.Errors = nil
+		var commitErr error
+		run(func(line int, fn func(tx domain.AccountTx) error) bool {
+			staged := newStagedTx(tx)
+			if err := fn(staged); err != nil {
+				report.Errors = append(report.Errors, domain.ImportLineError{Line: line, Error: err.Error()})
+				return len(report.Errors) < domain.MaxImportErrors
+			}
+			commitErr = staged.commit()
+			return commitErr == nil
+		})
+		switch {
+		case commitErr != nil:
+			return commitErr
+		case len(report.Errors) > 0:
+			return errImportRejected
+		case dryRun:
+			return errDryRun
+		case s.audit == nil:
+			return nil
+		}
+		return s.audit.Record(ctx, domain.AuditImport, kind, nil, report)
+	})
+	switch {
+	case errors.Is(err, errImportRejected):
+		first := report.Errors[0]
+		slog.InfoContext(ctx, "Import rejected", "kind", kind, "errors", len(report.Errors), "line", first.Line, "error", first.Error)
+		return report, nil
+	case errors.Is(err, errDryRun):
+		return report, nil
+	case err != nil:
+		return nil, err
+	}
+	report.Applied = true
+	slog.InfoContext(ctx, "Import applied", "kind", kind, "records", records)
+	return report, nil
+}
+
+// stagedTx holds one import record's writes over tx until commit, so a
+// record that fails partway leaves nothing behind.
+type stagedTx struct {
+	tx       domain.AccountTx
+	accounts map[string]*domain.Account
+	order    []string
+	outbox   []domain.OutboxMessage
+	journal  []domain.JournalEntry
+}
+
+func newStagedTx(tx domain.AccountTx) *stagedTx {
+	return &stagedTx{tx: tx, accounts: make(map[string]*domain.Account)}
+}
+
+func (s *stagedTx) FindByID(id string) (*domain.Account, error) {
+	account, ok := s.accounts[id]
+	if !ok {
+		return s.tx.FindByID(id)
+	}
+	found := *account
+	return &found, nil
+}
+
+func (s *stagedTx) Upsert(account *domain.Account) (*domain.Account, error) {
+	if _, ok := s.accounts[account.ID]; !ok {
+		s.order = append(s.order, account.ID)
+	}
+	stored := *account
+	s.accounts[account.ID] = &stored
+	return account, nil
+}
+
+func (s *stagedTx) Accounts() ([]domain.Account, error) {
+	accounts, err := s.tx.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	for i, a := range accounts {
+		if staged, ok := s.accounts[a.ID]; ok {
+			accounts[i] = *staged
+		}
+	}
+	for _, id := range s.order {
+		if !slices.ContainsFunc(accounts, func(a domain.Account) bool { return a.ID == id }) {
+			accounts = append(accounts, *s.accounts[id])
+		}
+	}
+	slices.SortFunc(accounts, func(a, b domain.Account) int { return strings.Compare(a.ID, b.ID) })
+	return accounts, nil
+}
+
+func (s *stagedTx) AppendOutbox(messages ...domain.OutboxMessage) error {
+	s.outbox = append(s.outbox, messages...)
+	return nil
+}
+
+func (s *stagedTx) PostJournal(entry domain.JournalEntry) error {
+	if !entry.Balanced() {
+		return domain.ErrUnbalancedEntry
+	}
+	s.journal = append(s.journal, entry)
+	return nil
+}
+
+// Clear is not needed by any import and would have to discard writes
+// outside the record.
+func (s *stagedTx) Clear() error {
+	return errors.New("Clear is not supported while staging an import record")
+}
+
+// commit passes the staged writes on to tx.
+func (s *stagedTx) commit() error {
+	for _, id := range s.order {
+		if _, err := s.tx.Upsert(s.accounts[id]); err != nil {
+			return err
+		}
+	}
+	if len(s.outbox) > 0 {
+		if err := s.tx.AppendOutbox(s.outbox...); err != nil {
+			return err
+		}
+	}
+	for _, entry := range s.journal {
+		if err := s.tx.PostJournal(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
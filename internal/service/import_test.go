@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+)
+
+func TestImportEvents(t *testing.T) {
+	audit := &mockAuditLog{}
+	repo := repository.NewInMemoryRepository()
+	s := NewAccountService(repo, WithAuditLog(audit))
+	ctx := context.Background()
+
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 50}},
+		{Line: 2, Event: domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 20}},
+		{Line: 3, Event: domain.EventRequest{Type: "withdraw", Origin: "300", Amount: 5}},
+	}, false)
+	if err != nil || !report.Applied || len(report.Errors) != 0 || report.Records != 3 {
+		t.Fatalf("Expected 3 records applied, got %+v (err %v)", report, err)
+	}
+	for id, want := range map[string]int{"100": 30, "300": 15} {
+		if balance, _ := s.GetBalance(ctx, id); balance != want {
+			t.Errorf("Expected %s to have %d, got %d", id, want, balance)
+		}
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != "import events" {
+		t.Errorf("Expected the import to be recorded, got %v", audit.actions)
+	}
+}
+
+func TestImportEvents_Atomic(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	s := NewAccountService(repo)
+	ctx := context.Background()
+
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 50}},
+		{Line: 4, Event: domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 80}},
+	}, false)
+	if err != nil || report.Applied || len(report.Errors) != 1 {
+		t.Fatalf("Expected one line error, got %+v (err %v)", report, err)
+	}
+	if e := report.Errors[0]; e.Line != 4 || e.Error != domain.ErrInsufficientFunds.Error() {
+		t.Errorf("Expected insufficient funds on line 4, got %+v", e)
+	}
+	if _, err := s.GetBalance(ctx, "100"); err != domain.ErrAccountNotFound {
+		t.Errorf("Expected the deposit on line 1 to be rolled back, got %v", err)
+	}
+}
+
+func TestImportEvents_ReportsEveryFailure(t *testing.T) {
+	s := NewAccountService(repository.NewInMemoryRepository())
+	ctx := context.Background()
+
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 50}},
+		{Line: 2, Event: domain.EventRequest{Type: "withdraw", Origin: "200", Amount: 10}},
+		{Line: 3, Event: domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 10}},
+		{Line: 4, Event: domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 80}},
+	}, true)
+	if err != nil || report.Applied || len(report.Errors) != 2 {
+		t.Fatalf("Expected two line errors, got %+v (err %v)", report, err)
+	}
+	if report.Errors[0].Line != 2 || report.Errors[1].Line != 4 {
+		t.Errorf("Expected errors on lines 2 and 4, got %+v", report.Errors)
+	}
+}
+
+func TestImportEvents_FailedRecordLeavesNoWrites(t *testing.T) {
+	s := NewAccountService(repository.NewInMemoryRepository())
+	ctx := context.Background()
+	s.Deposit(ctx, "100", 50)
+	s.Deposit(ctx, "200", 1)
+	s.SetFrozen(ctx, "200", true)
+
+	// The transfer debits 100 before it finds 200 frozen. The withdraw is
+	// only valid if that debit is dropped.
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "transfer", Origin: "100", Destination: "200", Amount: 30}},
+		{Line: 2, Event: domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 50}},
+	}, true)
+	if err != nil || len(report.Errors) != 1 || report.Errors[0].Line != 1 {
+		t.Errorf("Expected only line 1 to fail, got %+v (err %v)", report, err)
+	}
+}
+
+func TestImportEvents_ErrorsCapped(t *testing.T) {
+	s := NewAccountService(repository.NewInMemoryRepository())
+	events := make([]domain.ImportedEvent, domain.MaxImportErrors+10)
+	for i := range events {
+		events[i] = domain.ImportedEvent{Line: i + 1, Event: domain.EventRequest{Type: "withdraw", Origin: "200", Amount: 10}}
+	}
+
+	report, err := s.ImportEvents(context.Background(), events, false)
+	if err != nil || len(report.Errors) != domain.MaxImportErrors {
+		t.Errorf("Expected %d line errors, got %d (err %v)", domain.MaxImportErrors, len(report.Errors), err)
+	}
+}
+
+func TestImportEvents_Screened(t *testing.T) {
+	s := NewAccountService(repository.NewInMemoryRepository(), WithImportScreener(blockingScreener{blocked: "666"}))
+	ctx := context.Background()
+
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 50}},
+		{Line: 2, Event: domain.EventRequest{Type: "transfer", Origin: "100", Destination: "666", Amount: 20}},
+	}, false)
+	if err != nil || report.Applied || len(report.Errors) != 1 {
+		t.Fatalf("Expected one line error, got %+v (err %v)", report, err)
+	}
+	if e := report.Errors[0]; e.Line != 2 || e.Error != (&domain.ScreeningError{AccountID: "666"}).Error() {
+		t.Errorf("Expected the transfer on line 2 to be blocked, got %+v", e)
+	}
+	if _, err := s.GetBalance(ctx, "100"); err != domain.ErrAccountNotFound {
+		t.Errorf("Expected the import to be rolled back, got %v", err)
+	}
+}
+
+func TestImportEvents_DryRun(t *testing.T) {
+	audit := &mockAuditLog{}
+	repo := repository.NewInMemoryRepository()
+	s := NewAccountService(repo, WithAuditLog(audit))
+	ctx := context.Background()
+
+	report, err := s.ImportEvents(ctx, []domain.ImportedEvent{
+		{Line: 1, Event: domain.EventRequest{Type: "deposit", Destination: "100", Amount: 50}},
+	}, true)
+	if err != nil || report.Applied || !report.DryRun || len(report.Errors) != 0 {
+		t.Fatalf("Expected a clean dry run, got %+v (err %v)", report, err)
+	}
+	if _, err := s.GetBalance(ctx, "100"); err != domain.ErrAccountNotFound {
+		t.Errorf("Expected nothing applied, got %v", err)
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("Expected a dry run not to be recorded, got %v", audit.actions)
+	}
+}
+
+func TestImportAccounts(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	s := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	s.Deposit(ctx, "100", 50)
+	report, err := s.ImportAccounts(ctx, []domain.ImportedAccount{
+		{Line: 2, Account: domain.Account{ID: "100", Balance: 20}},
+		{Line: 3, Account: domain.Account{ID: "200", Balance: 70, Frozen: true}},
+	}, false)
+	if err != nil || !report.Applied {
+		t.Fatalf("Expected the snapshot applied, got %+v (err %v)", report, err)
+	}
+	if balance, _ := s.GetBalance(ctx, "100"); balance != 20 {
+		t.Errorf("Expected 100 to be set to 20, got %d", balance)
+	}
+	if _, err := s.Deposit(ctx, "200", 1); err != domain.ErrAccountFrozen {
+		t.Errorf("Expected 200 to be imported frozen, got %v", err)
+	}
+	tb, _ := ledger.TrialBalance(ctx)
+	if !tb.OK() {
+		t.Errorf("Expected the books to balance after an account import, got %+v", tb)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const journalPageSize = 1000
+
+// LedgerService reads the double-entry journal that AccountService posts
+// to.
+type LedgerService struct {
+	repo domain.JournalRepository
+}
+
+func NewLedgerService(repo domain.JournalRepository) *LedgerService {
+	return &LedgerService{repo: repo}
+}
+
+// TrialBalance totals every journal entry and checks the result against the
+// stored account balances, both taken at the same journal position.
+func (s *LedgerService) TrialBalance(ctx context.Context) (*domain.TrialBalance, error) {
+	accounts, head, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*domain.LedgerBalance)
+	tb := &domain.TrialBalance{JournalSeq: head}
+	err = s.scan(ctx, 0, head, func(e domain.JournalEntry) error {
+		for _, l := range e.Lines {
+			t, ok := totals[l.Account]
+			if !ok {
+				t = &domain.LedgerBalance{Account: l.Account}
+				totals[l.Account] = t
+			}
+			t.Debit += l.Debit
+			t.Credit += l.Credit
+			tb.TotalDebit += l.Debit
+			tb.TotalCredit += l.Credit
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tb.Balanced = tb.TotalDebit == tb.TotalCredit
+
+	tb.Accounts = make([]domain.LedgerBalance, 0, len(totals))
+	for _, t := range totals {
+		tb.Accounts = append(tb.Accounts, *t)
+	}
+	slices.SortFunc(tb.Accounts, func(a, b domain.LedgerBalance) int {
+		return cmp.Compare(a.Account, b.Account)
+	})
+
+	stored := make(map[string]int, len(accounts))
+	for _, a := range accounts {
+		stored[a.ID] = a.Balance
+	}
+	for _, t := range tb.Accounts {
+		if t.Account == domain.CashAccount {
+			continue
+		}
+		if ledger := t.Credit - t.Debit; ledger != stored[t.Account] {
+			tb.Mismatches = append(tb.Mismatches, domain.BalanceMismatch{Account: t.Account, Stored: stored[t.Account], Ledger: ledger})
+		}
+		delete(stored, t.Account)
+	}
+	for _, a := range accounts {
+		if balance, ok := stored[a.ID]; ok && balance != 0 {
+			tb.Mismatches = append(tb.Mismatches, domain.BalanceMismatch{Account: a.ID, Stored: balance})
+		}
+	}
+
+	if !tb.OK() {
+		slog.ErrorContext(ctx, "Trial balance failed",
+			"total_debit", tb.TotalDebit,
+			"total_credit", tb.TotalCredit,
+			"mismatches", len(tb.Mismatches),
+		)
+	}
+	return tb, nil
+}
+
+func (s *LedgerService) BalanceAsOf(ctx context.Context, accountID string, at time.Time) (int, error) {
+	balance, found, err := s.repo.BalanceAt(ctx, accountID, at)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, domain.ErrAccountNotFound
+	}
+	return balance, nil
+}
+
+// Statement pages through the account's postings so that a long range is
+// never held in memory. The opening balance is the balance just before from.
+func (s *LedgerService) Statement(ctx context.Context, accountID string, from, to time.Time, w domain.StatementWriter) error {
+	_, found, err := s.repo.BalanceAt(ctx, accountID, to.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrAccountNotFound
+	}
+	opening, _, err := s.repo.BalanceAt(ctx, accountID, from.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	postings, err := s.repo.Postings(ctx, accountID, from, to, 0, journalPageSize)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Begin(domain.StatementHeader{AccountID: accountID, From: from, To: to, OpeningBalance: opening}); err != nil {
+		return err
+	}
+	balance := opening
+	totals := make(map[string]*domain.StatementTotal)
+	for len(postings) > 0 {
+		for _, p := range postings {
+			balance += p.Amount
+			t, ok := totals[p.Type]
+			if !ok {
+				t = &domain.StatementTotal{Type: p.Type}
+				totals[p.Type] = t
+			}
+			t.Count++
+			if p.Amount >= 0 {
+				t.Credits += p.Amount
+			} else {
+				t.Debits -= p.Amount
+			}
+			if err := w.Line(domain.StatementLine{Posting: p, Balance: balance}); err != nil {
+				return err
+			}
+		}
+		if len(postings) < journalPageSize {
+			break
+		}
+		postings, err = s.repo.Postings(ctx, accountID, from, to, postings[len(postings)-1].Seq, journalPageSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary := domain.StatementSummary{Totals: make([]domain.StatementTotal, 0, len(totals)), ClosingBalance: balance}
+	for _, t := range totals {
+		summary.Totals = append(summary.Totals, *t)
+	}
+	slices.SortFunc(summary.Totals, func(a, b domain.StatementTotal) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+	return w.End(summary)
+}
+
+func (s *LedgerService) ExportAccounts(ctx context.Context, fn func(domain.Account) error) error {
+	accounts, _, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportEvents writes the journal up to its current head. Account imports
+// come out as the deposit or withdraw that has the same effect.
+func (s *LedgerService) ExportEvents(ctx context.Context, fn func(domain.ExportedEvent) error) error {
+	_, head, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	return s.scan(ctx, 0, head, func(e domain.JournalEntry) error {
+		return fn(domain.ExportedEvent{Seq: e.Seq, Time: e.Time, EventRequest: eventFromEntry(e)})
+	})
+}
+
+// eventFromEntry inverts the postings made by AccountService.
+func eventFromEntry(e domain.JournalEntry) domain.EventRequest {
+	event := domain.EventRequest{Type: "transfer"}
+	for _, l := range e.Lines {
+		switch {
+		case l.Account == domain.CashAccount:
+			event.Type = "deposit"
+			if l.Credit > 0 {
+				event.Type = "withdraw"
+			}
+		case l.Credit > 0:
+			event.Destination = l.Account
+			event.Amount = l.Credit
+		default:
+			event.Origin = l.Account
+			event.Amount = l.Debit
+		}
+	}
+	return event
+}
+
+// scan calls fn for every journal entry with a Seq in (afterSeq, upTo],
+// stopping at the first error.
+func (s *LedgerService) scan(ctx context.Context, afterSeq, upTo uint64, fn func(domain.JournalEntry) error) error {
+	for afterSeq < upTo {
+		entries, err := s.repo.Journal(ctx, afterSeq, journalPageSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		for _, e := range entries {
+			if e.Seq > upTo {
+				return nil
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+			afterSeq = e.Seq
+		}
+	}
+	return nil
+}
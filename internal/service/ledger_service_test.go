@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+)
+
+func TestTrialBalance(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	accounts.Withdraw(ctx, "100", 5)
+	accounts.Transfer(ctx, "100", "300", 15)
+	accounts.Withdraw(ctx, "300", 100) // insufficient funds, posts nothing
+
+	tb, err := ledger.TrialBalance(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !tb.OK() {
+		t.Errorf("Expected the books to balance, got %+v", tb)
+	}
+	if tb.JournalSeq != 3 || tb.TotalDebit != 70 || tb.TotalCredit != 70 {
+		t.Errorf("Expected 3 entries totalling 70, got %+v", tb)
+	}
+	want := []domain.LedgerBalance{
+		{Account: "100", Debit: 20, Credit: 50},
+		{Account: "300", Credit: 15},
+		{Account: domain.CashAccount, Debit: 50, Credit: 5},
+	}
+	for i, w := range want {
+		if i >= len(tb.Accounts) || tb.Accounts[i] != w {
+			t.Errorf("Expected ledger account %+v, got %+v", w, tb.Accounts)
+			break
+		}
+	}
+}
+
+func TestTrialBalance_Mismatch(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	// Writing around the service creates money without a journal entry.
+	repo.Upsert(ctx, &domain.Account{ID: "100", Balance: 80})
+	repo.Upsert(ctx, &domain.Account{ID: "200", Balance: 5})
+
+	tb, _ := ledger.TrialBalance(ctx)
+	if !tb.Balanced || tb.OK() {
+		t.Errorf("Expected balanced journal with mismatches, got %+v", tb)
+	}
+	if len(tb.Mismatches) != 2 || tb.Mismatches[0] != (domain.BalanceMismatch{Account: "100", Stored: 80, Ledger: 50}) {
+		t.Errorf("Expected mismatches for 100 and 200, got %+v", tb.Mismatches)
+	}
+}
+
+func TestBalanceAsOf(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	before := time.Now().Add(-time.Minute)
+	accounts.Deposit(ctx, "100", 50)
+	accounts.Withdraw(ctx, "100", 10)
+
+	if _, err := ledger.BalanceAsOf(ctx, "100", before); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound before the first deposit, got %v", err)
+	}
+	balance, err := ledger.BalanceAsOf(ctx, "100", time.Now().Add(time.Minute))
+	if err != nil || balance != 40 {
+		t.Errorf("Expected balance 40, got %d (%v)", balance, err)
+	}
+}
+
+type recordedStatement struct {
+	header  domain.StatementHeader
+	lines   []domain.StatementLine
+	summary *domain.StatementSummary
+}
+
+func (s *recordedStatement) Begin(header domain.StatementHeader) error {
+	s.header = header
+	return nil
+}
+
+func (s *recordedStatement) Line(line domain.StatementLine) error {
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+func (s *recordedStatement) End(summary domain.StatementSummary) error {
+	s.summary = &summary
+	return nil
+}
+
+func TestStatement(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	from := time.Now()
+	accounts.Withdraw(ctx, "100", 5)
+	accounts.Transfer(ctx, "100", "300", 15)
+	accounts.Transfer(ctx, "300", "100", 3)
+	accounts.Deposit(ctx, "100", 10)
+	to := time.Now().Add(time.Second)
+
+	var s recordedStatement
+	if err := ledger.Statement(ctx, "100", from, to, &s); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if s.header.OpeningBalance != 50 {
+		t.Errorf("Expected opening balance 50, got %d", s.header.OpeningBalance)
+	}
+	balances := []int{45, 30, 33, 43}
+	if len(s.lines) != len(balances) {
+		t.Fatalf("Expected %d lines, got %+v", len(balances), s.lines)
+	}
+	for i, b := range balances {
+		if s.lines[i].Balance != b {
+			t.Errorf("Expected running balance %d on line %d, got %d", b, i, s.lines[i].Balance)
+		}
+	}
+	if s.lines[1].Counterparty != "300" || s.lines[2].Counterparty != "300" {
+		t.Errorf("Expected transfers with 300, got %+v", s.lines)
+	}
+	want := []domain.StatementTotal{
+		{Type: "deposit", Count: 1, Credits: 10},
+		{Type: "transfer", Count: 2, Credits: 3, Debits: 15},
+		{Type: "withdraw", Count: 1, Debits: 5},
+	}
+	if s.summary == nil || s.summary.ClosingBalance != 43 || len(s.summary.Totals) != len(want) {
+		t.Fatalf("Expected closing balance 43 with %d totals, got %+v", len(want), s.summary)
+	}
+	for i, w := range want {
+		if s.summary.Totals[i] != w {
+			t.Errorf("Expected total %+v, got %+v", w, s.summary.Totals[i])
+		}
+	}
+}
+
+func TestStatement_Paged(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	n := journalPageSize*2 + 7
+	for i := 0; i < n; i++ {
+		accounts.Deposit(ctx, "100", 1)
+	}
+
+	var s recordedStatement
+	if err := ledger.Statement(ctx, "100", time.Time{}, time.Now().Add(time.Second), &s); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(s.lines) != n || s.summary.ClosingBalance != n {
+		t.Errorf("Expected %d lines closing at %d, got %d closing at %d", n, n, len(s.lines), s.summary.ClosingBalance)
+	}
+}
+
+func TestStatement_NotFound(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	before := time.Now()
+	accounts.Deposit(ctx, "100", 50)
+
+	var s recordedStatement
+	if err := ledger.Statement(ctx, "100", time.Time{}, before, &s); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound before the first deposit, got %v", err)
+	}
+	if err := ledger.Statement(ctx, "200", time.Time{}, time.Now(), &s); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound for an unknown account, got %v", err)
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	accounts.Transfer(ctx, "100", "300", 15)
+	accounts.Withdraw(ctx, "300", 5)
+	accounts.ImportAccounts(ctx, []domain.ImportedAccount{{Line: 1, Account: domain.Account{ID: "400", Balance: 9}}}, false)
+
+	var events []domain.ImportedEvent
+	err := ledger.ExportEvents(ctx, func(e domain.ExportedEvent) error {
+		events = append(events, domain.ImportedEvent{Line: int(e.Seq), Event: e.EventRequest})
+		return nil
+	})
+	if err != nil || len(events) != 4 {
+		t.Fatalf("Expected 4 exported events, got %+v (err %v)", events, err)
+	}
+	if events[1].Event != (domain.EventRequest{Type: "transfer", Origin: "100", Destination: "300", Amount: 15}) {
+		t.Errorf("Expected the transfer to export as one, got %+v", events[1].Event)
+	}
+
+	copyRepo := repository.NewInMemoryRepository()
+	copyAccounts := NewAccountService(copyRepo)
+	if report, err := copyAccounts.ImportEvents(ctx, events, false); err != nil || !report.Applied {
+		t.Fatalf("Expected the export to import cleanly, got %+v (err %v)", report, err)
+	}
+	err = ledger.ExportAccounts(ctx, func(a domain.Account) error {
+		if balance, _ := copyAccounts.GetBalance(ctx, a.ID); balance != a.Balance {
+			t.Errorf("Expected %s to have %d in the copy, got %d", a.ID, a.Balance, balance)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error exporting accounts, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// SnapshotService dumps and restores every account.
+type SnapshotService struct {
+	repo  domain.SnapshotRepository
+	audit domain.AuditLog
+	now   func() time.Time
+}
+
+type SnapshotServiceOption func(*SnapshotService)
+
+// WithSnapshotAuditLog records restores once they commit, so an abandoned
+// restore leaves no entry.
+func WithSnapshotAuditLog(audit domain.AuditLog) SnapshotServiceOption {
+	return func(s *SnapshotService) {
+		s.audit = audit
+	}
+}
+
+func NewSnapshotService(repo domain.SnapshotRepository, opts ...SnapshotServiceOption) *SnapshotService {
+	s := &SnapshotService{
+		repo: repo,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Snapshot copies the accounts together with the journal position they
+// reflect. Writers only wait for the copy, not for the response.
+func (s *SnapshotService) Snapshot(ctx context.Context) (*domain.Snapshot, error) {
+	accounts, head, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		accounts = []domain.Account{}
+	}
+	snapshot := &domain.Snapshot{
+		Version:    domain.SnapshotVersion,
+		TakenAt:    s.now().UTC(),
+		JournalSeq: head,
+		Accounts:   accounts,
+	}
+	snapshot.Checksum = snapshot.Sum()
+	return snapshot, nil
+}
+
+// Restore replaces all state with the snapshot, as a /reset followed by one
+// "restore" journal entry per funded account. Past history is dropped;
+// pending outbox messages are kept for the relay.
+func (s *SnapshotService) Restore(ctx context.Context, snapshot *domain.Snapshot) error {
+	if err := snapshot.Verify(); err != nil {
+		return err
+	}
+	var before stateRecord
+	err := s.repo.Transaction(ctx, func(tx domain.AccountTx) error {
+		var err error
+		if before, err = replacedState(tx); err != nil {
+			return err
+		}
+		if err := tx.Clear(); err != nil {
+			return err
+		}
+		for _, a := range snapshot.Accounts {
+			if _, err := tx.Upsert(&a); err != nil {
+				return err
+			}
+			err := postJournal(tx, "restore",
+				domain.JournalLine{Account: domain.CashAccount, Debit: a.Balance},
+				domain.JournalLine{Account: a.ID, Credit: a.Balance},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Snapshot restored", "accounts", len(snapshot.Accounts), "taken_at", snapshot.TakenAt, "checksum", snapshot.Checksum)
+	if s.audit == nil {
+		return nil
+	}
+	err = s.audit.Record(ctx, domain.AuditRestore, "", before, restoreRecord{
+		Version:    snapshot.Version,
+		TakenAt:    snapshot.TakenAt,
+		JournalSeq: snapshot.JournalSeq,
+		Accounts:   len(snapshot.Accounts),
+		Checksum:   snapshot.Checksum,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error recording restore in audit log", "error", err)
+		return err
+	}
+	return nil
+}
+
+// restoreRecord is what the audit log keeps of a restored snapshot.
+type restoreRecord struct {
+	Version    int       `json:"version"`
+	TakenAt    time.Time `json:"taken_at"`
+	JournalSeq uint64    `json:"journal_seq"`
+	Accounts   int       `json:"accounts"`
+	Checksum   string    `json:"checksum"`
+}
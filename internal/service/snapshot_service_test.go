@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	ledger := NewLedgerService(repo)
+	audit := &mockAuditLog{}
+	snapshots := NewSnapshotService(repo, WithSnapshotAuditLog(audit))
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	accounts.Transfer(ctx, "100", "300", 20)
+	accounts.SetFrozen(ctx, "300", true)
+
+	snapshot, err := snapshots.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if snapshot.Version != domain.SnapshotVersion || snapshot.JournalSeq != 2 || len(snapshot.Accounts) != 2 {
+		t.Errorf("Expected a version %d snapshot of 2 accounts at seq 2, got %+v", domain.SnapshotVersion, snapshot)
+	}
+	if err := snapshot.Verify(); err != nil {
+		t.Errorf("Expected a fresh snapshot to verify, got %v", err)
+	}
+
+	accounts.Deposit(ctx, "100", 1000)
+	accounts.Deposit(ctx, "400", 7)
+	if err := snapshots.Restore(ctx, snapshot); err != nil {
+		t.Fatalf("Expected no error restoring, got %v", err)
+	}
+
+	for id, want := range map[string]int{"100": 30, "300": 20} {
+		if balance, _ := accounts.GetBalance(ctx, id); balance != want {
+			t.Errorf("Expected %s to be restored to %d, got %d", id, want, balance)
+		}
+	}
+	if _, err := accounts.GetBalance(ctx, "400"); !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("Expected 400 to be gone after the restore, got %v", err)
+	}
+	if _, err := accounts.Deposit(ctx, "300", 1); !errors.Is(err, domain.ErrAccountFrozen) {
+		t.Errorf("Expected 300 to be restored frozen, got %v", err)
+	}
+	if tb, _ := ledger.TrialBalance(ctx); !tb.OK() {
+		t.Errorf("Expected the books to balance after a restore, got %+v", tb)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != "restore " {
+		t.Fatalf("Expected the restore to be recorded, got %v", audit.actions)
+	}
+	if before, ok := audit.befores[0].(stateRecord); !ok || before.Accounts != 3 || before.Total != 1057 {
+		t.Errorf("Expected the replaced accounts recorded before the restore, got %+v", audit.befores[0])
+	}
+}
+
+func TestRestore_Rejected(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	snapshots := NewSnapshotService(repo)
+	ctx := context.Background()
+
+	accounts.Deposit(ctx, "100", 50)
+	valid, _ := snapshots.Snapshot(ctx)
+
+	sealed := func(s domain.Snapshot) *domain.Snapshot {
+		s.Checksum = s.Sum()
+		return &s
+	}
+	tampered := *valid
+	tampered.Accounts = []domain.Account{{ID: "100", Balance: 5000}}
+	future := *valid
+	future.Version = domain.SnapshotVersion + 1
+	duplicate := *valid
+	duplicate.Accounts = []domain.Account{{ID: "100", Balance: 1}, {ID: "100", Balance: 2}}
+	negative := *valid
+	negative.Accounts = []domain.Account{{ID: "100", Balance: -1}}
+
+	tests := []struct {
+		name     string
+		snapshot *domain.Snapshot
+		want     error
+	}{
+		{"tampered", &tampered, domain.ErrSnapshotChecksum},
+		{"future version", sealed(future), domain.ErrSnapshotVersion},
+		{"duplicate", sealed(duplicate), domain.ErrInvalidSnapshot},
+		{"negative", sealed(negative), domain.ErrInvalidSnapshot},
+	}
+	for _, tt := range tests {
+		if err := snapshots.Restore(ctx, tt.snapshot); !errors.Is(err, tt.want) {
+			t.Errorf("Expected %v for a %s snapshot, got %v", tt.want, tt.name, err)
+		}
+	}
+	if balance, _ := accounts.GetBalance(ctx, "100"); balance != 50 {
+		t.Errorf("Expected rejected restores to change nothing, got balance %d", balance)
+	}
+}
+
+func TestRestore_AbandonedNotAudited(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	accounts := NewAccountService(repo)
+	audit := &mockAuditLog{}
+	snapshots := NewSnapshotService(repo, WithSnapshotAuditLog(audit))
+	snapshot, _ := snapshots.Snapshot(context.Background())
+	accounts.Deposit(context.Background(), "100", 50)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := snapshots.Restore(ctx, snapshot); err == nil {
+		t.Errorf("Expected an error restoring with a cancelled context")
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("Expected an abandoned restore not to be recorded, got %v", audit.actions)
+	}
+	if balance, _ := accounts.GetBalance(context.Background(), "100"); balance != 50 {
+		t.Errorf("Expected the restore not to apply, got balance %d", balance)
+	}
+}
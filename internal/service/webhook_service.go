@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookIDHeader        = "X-Webhook-ID"
+)
+
+type WebhookConfig struct {
+	// MaxAttempts is the number of deliveries tried before a payload is
+	// dead-lettered.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles on each
+	// subsequent retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds a single delivery request.
+	Timeout time.Duration
+	// Workers is the number of concurrent deliveries.
+	Workers int
+	// QueueSize is the number of deliveries buffered before new payloads are
+	// dead-lettered instead of blocking event processing.
+	QueueSize int
+	// MaxLogEntries bounds the delivery log; the oldest entries are dropped.
+	MaxLogEntries int
+	// MaxDeadLetters bounds the dead-letter list; the oldest entries are
+	// dropped once it is full.
+	MaxDeadLetters int
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        10 * time.Second,
+		Workers:        4,
+		QueueSize:      1024,
+		MaxLogEntries:  1000,
+		MaxDeadLetters: 1000,
+	}
+}
+
+type webhookJob struct {
+	webhook domain.Webhook
+	payload domain.WebhookPayload
+	attempt int
+}
+
+// WebhookService notifies registered endpoints of processed events. It
+// implements domain.EventListener and delivers asynchronously, so event
+// processing is never blocked by a slow partner.
+type WebhookService struct {
+	cfg    WebhookConfig
+	client *http.Client
+
+	webhooks    map[string]*domain.Webhook
+	deliveries  []domain.WebhookDelivery
+	deadLetters []domain.WebhookDeadLetter
+	mu          sync.RWMutex
+
+	queue  chan webhookJob
+	done   chan struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewWebhookService(cfg WebhookConfig) *WebhookService {
+	s := &WebhookService{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		webhooks: make(map[string]*domain.Webhook),
+		queue:    make(chan webhookJob, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	return s
+}
+
+// Close stops the delivery workers. Pending retries are abandoned.
+func (s *WebhookService) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Check implements a readiness check, failing once the service is closed.
+func (s *WebhookService) Check(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return errors.New("webhook service closed")
+	}
+	return nil
+}
+
+func (s *WebhookService) Register(hook domain.Webhook) (*domain.Webhook, error) {
+	hook.ID = newID("wh")
+	hook.CreatedAt = time.Now().UTC()
+	if hook.Secret == "" {
+		hook.Secret = newSecret()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhooks[hook.ID] = &hook
+	registered := hook
+	return &registered, nil
+}
+
+// List returns all registered webhooks with their secrets redacted.
+func (s *WebhookService) List() ([]domain.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := make([]domain.Webhook, 0, len(s.webhooks))
+	for _, hook := range s.webhooks {
+		h := *hook
+		h.Secret = ""
+		hooks = append(hooks, h)
+	}
+	slices.SortFunc(hooks, func(a, b domain.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return hooks, nil
+}
+
+func (s *WebhookService) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+// Deliveries returns the delivery log, optionally filtered by webhook.
+func (s *WebhookService) Deliveries(webhookID string) ([]domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(s.deliveries))
+	for _, d := range s.deliveries {
+		if webhookID == "" || d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookService) DeadLetters() ([]domain.WebhookDeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.deadLetters), nil
+}
+
+// Redeliver removes a dead letter and queues it again with a fresh attempt
+// budget. The webhook must still be registered.
+func (s *WebhookService) Redeliver(deadLetterID string) error {
+	s.mu.Lock()
+	i := slices.IndexFunc(s.deadLetters, func(dl domain.WebhookDeadLetter) bool {
+		return dl.ID == deadLetterID
+	})
+	if i < 0 {
+		s.mu.Unlock()
+		return domain.ErrDeadLetterNotFound
+	}
+	dl := s.deadLetters[i]
+	hook, ok := s.webhooks[dl.WebhookID]
+	if !ok {
+		s.mu.Unlock()
+		return domain.ErrWebhookNotFound
+	}
+	s.deadLetters = slices.Delete(s.deadLetters, i, i+1)
+	job := webhookJob{webhook: *hook, payload: dl.Payload, attempt: 1}
+	s.mu.Unlock()
+
+	s.enqueue(job)
+	return nil
+}
+
+// OnEvent implements domain.EventListener.
+func (s *WebhookService) OnEvent(ctx context.Context, event domain.EventRequest, resp *domain.EventResponse) {
+	payload := domain.WebhookPayload{
+		ID:        newID("evt"),
+		Type:      event.Type,
+		CreatedAt: time.Now().UTC(),
+		Event:     event,
+		Result:    resp.Clone(),
+	}
+
+	s.mu.RLock()
+	var matches []domain.Webhook
+	for _, hook := range s.webhooks {
+		if webhookMatches(hook, event) {
+			matches = append(matches, *hook)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, hook := range matches {
+		s.enqueue(webhookJob{webhook: hook, payload: payload, attempt: 1})
+	}
+}
+
+func webhookMatches(hook *domain.Webhook, event domain.EventRequest) bool {
+	if len(hook.EventTypes) > 0 && !slices.Contains(hook.EventTypes, event.Type) {
+		return false
+	}
+	if len(hook.Accounts) == 0 {
+		return true
+	}
+	return (event.Origin != "" && slices.Contains(hook.Accounts, event.Origin)) ||
+		(event.Destination != "" && slices.Contains(hook.Accounts, event.Destination))
+}
+
+// enqueue hands a job to the workers without blocking. A full queue or a
+// closed service dead-letters the payload so it can be redelivered later.
+func (s *WebhookService) enqueue(job webhookJob) {
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+	if closed {
+		s.deadLetter(job, "webhook service closed")
+		return
+	}
+
+	select {
+	case s.queue <- job:
+	default:
+		s.deadLetter(job, "delivery queue full")
+	}
+}
+
+func (s *WebhookService) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case job := <-s.queue:
+			s.deliver(job)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *WebhookService) deliver(job webhookJob) {
+	body, err := json.Marshal(job.payload)
+	if err != nil {
+		s.deadLetter(job, err.Error())
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := s.post(job, body)
+	delivery := domain.WebhookDelivery{
+		ID:         newID("dlv"),
+		WebhookID:  job.webhook.ID,
+		PayloadID:  job.payload.ID,
+		Attempt:    job.attempt,
+		StatusCode: statusCode,
+		Success:    err == nil,
+		DurationMS: time.Since(start).Milliseconds(),
+		AttemptAt:  start.UTC(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	s.logDelivery(delivery)
+
+	if err == nil {
+		return
+	}
+	if job.attempt >= s.cfg.MaxAttempts {
+		s.deadLetter(job, err.Error())
+		return
+	}
+
+	backoff := s.backoff(job.attempt)
+	job.attempt++
+	time.AfterFunc(backoff, func() {
+		s.enqueue(job)
+	})
+}
+
+func (s *WebhookService) post(job webhookJob, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, job.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, job.payload.ID)
+	req.Header.Set(WebhookSignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhook(job.webhook.Secret, timestamp, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	backoff := s.cfg.InitialBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > s.cfg.MaxBackoff {
+		return s.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func (s *WebhookService) logDelivery(delivery domain.WebhookDelivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, delivery)
+	if over := len(s.deliveries) - s.cfg.MaxLogEntries; over > 0 {
+		s.deliveries = slices.Delete(s.deliveries, 0, over)
+	}
+}
+
+func (s *WebhookService) deadLetter(job webhookJob, reason string) {
+	slog.Warn("Webhook payload dead-lettered", "webhook_id", job.webhook.ID, "payload_id", job.payload.ID,
+		"attempts", job.attempt, "reason", reason)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deadLetters = append(s.deadLetters, domain.WebhookDeadLetter{
+		ID:        newID("dl"),
+		WebhookID: job.webhook.ID,
+		Payload:   job.payload,
+		Attempts:  job.attempt,
+		LastError: reason,
+		FailedAt:  time.Now().UTC(),
+	})
+	if over := len(s.deadLetters) - s.cfg.MaxDeadLetters; over > 0 {
+		slog.Warn("Webhook dead letters dropped", "count", over, "max_dead_letters", s.cfg.MaxDeadLetters)
+		s.deadLetters = slices.Delete(s.deadLetters, 0, over)
+	}
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>". Receivers
+// recompute it from the t= and v1= parts of the X-Webhook-Signature header.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
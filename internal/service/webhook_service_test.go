@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+)
+
+func testWebhookConfig() WebhookConfig {
+	cfg := DefaultWebhookConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	cfg.MaxAttempts = 3
+	return cfg
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhookDeliversSignedPayload(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	webhookService := NewWebhookService(testWebhookConfig())
+	defer webhookService.Close()
+
+	hook, _ := webhookService.Register(domain.Webhook{URL: receiver.URL, Secret: "s3cr3t"})
+
+	eventService := NewEventService(NewAccountService(repository.NewInMemoryRepository()))
+	eventService.AddListener(webhookService)
+	eventService.ProcessEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 10})
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected webhook delivery")
+	}
+	body := <-bodies
+
+	var timestamp int64
+	var signature string
+	fmt.Sscanf(strings.Replace(req.Header.Get(WebhookSignatureHeader), ",v1=", " ", 1), "t=%d %s", &timestamp, &signature)
+	if signature != SignWebhook("s3cr3t", timestamp, body) {
+		t.Errorf("Expected valid signature, got %q", req.Header.Get(WebhookSignatureHeader))
+	}
+	if !strings.Contains(string(body), `"balance":10`) {
+		t.Errorf("Expected payload to include result, got %s", body)
+	}
+
+	waitFor(t, func() bool {
+		deliveries, _ := webhookService.Deliveries(hook.ID)
+		return len(deliveries) == 1 && deliveries[0].Success
+	})
+}
+
+func TestWebhookFiltersByAccountAndType(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	webhookService := NewWebhookService(testWebhookConfig())
+	defer webhookService.Close()
+
+	webhookService.Register(domain.Webhook{URL: receiver.URL, Accounts: []string{"100"}, EventTypes: []string{"withdraw"}})
+
+	webhookService.OnEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 1}, &domain.EventResponse{})
+	webhookService.OnEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "200", Amount: 1}, &domain.EventResponse{})
+	webhookService.OnEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 1}, &domain.EventResponse{})
+
+	waitFor(t, func() bool { return calls.Load() >= 1 })
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 delivery, got %d", calls.Load())
+	}
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	webhookService := NewWebhookService(testWebhookConfig())
+	defer webhookService.Close()
+
+	hook, _ := webhookService.Register(domain.Webhook{URL: receiver.URL})
+	webhookService.OnEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 1}, &domain.EventResponse{})
+
+	waitFor(t, func() bool {
+		deliveries, _ := webhookService.Deliveries(hook.ID)
+		return len(deliveries) == 3
+	})
+	deliveries, _ := webhookService.Deliveries(hook.ID)
+	if deliveries[0].StatusCode != http.StatusServiceUnavailable || deliveries[2].Attempt != 3 || !deliveries[2].Success {
+		t.Errorf("Unexpected delivery log: %+v", deliveries)
+	}
+	deadLetters, _ := webhookService.DeadLetters()
+	if len(deadLetters) != 0 {
+		t.Errorf("Expected no dead letters, got %d", len(deadLetters))
+	}
+}
+
+func TestWebhookDeadLetterAndRedeliver(t *testing.T) {
+	var healthy atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	webhookService := NewWebhookService(testWebhookConfig())
+	defer webhookService.Close()
+
+	hook, _ := webhookService.Register(domain.Webhook{URL: receiver.URL})
+	webhookService.OnEvent(context.Background(), domain.EventRequest{Type: "deposit", Destination: "100", Amount: 1}, &domain.EventResponse{})
+
+	var deadLetters []domain.WebhookDeadLetter
+	waitFor(t, func() bool {
+		deadLetters, _ = webhookService.DeadLetters()
+		return len(deadLetters) == 1
+	})
+	if deadLetters[0].Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", deadLetters[0].Attempts)
+	}
+
+	healthy.Store(true)
+	if err := webhookService.Redeliver(deadLetters[0].ID); err != nil {
+		t.Fatalf("Expected no error redelivering: %v", err)
+	}
+	waitFor(t, func() bool {
+		deliveries, _ := webhookService.Deliveries(hook.ID)
+		return len(deliveries) == 4 && deliveries[3].Success
+	})
+
+	if err := webhookService.Redeliver("missing"); err != domain.ErrDeadLetterNotFound {
+		t.Errorf("Expected ErrDeadLetterNotFound, got %v", err)
+	}
+}
+
+func TestWebhookDeadLettersCapped(t *testing.T) {
+	cfg := testWebhookConfig()
+	cfg.Workers = 0
+	cfg.MaxDeadLetters = 2
+	webhookService := NewWebhookService(cfg)
+	defer webhookService.Close()
+
+	for _, id := range []string{"p-1", "p-2", "p-3"} {
+		webhookService.deadLetter(webhookJob{payload: domain.WebhookPayload{ID: id}}, "failed")
+	}
+
+	deadLetters, _ := webhookService.DeadLetters()
+	if len(deadLetters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(deadLetters))
+	}
+	if deadLetters[0].Payload.ID != "p-2" || deadLetters[1].Payload.ID != "p-3" {
+		t.Errorf("Expected the oldest dead letter to be dropped, got %s and %s", deadLetters[0].Payload.ID, deadLetters[1].Payload.ID)
+	}
+}
+
+func TestWebhookListRedactsSecret(t *testing.T) {
+	webhookService := NewWebhookService(testWebhookConfig())
+	defer webhookService.Close()
+
+	hook, _ := webhookService.Register(domain.Webhook{URL: "http://example.com"})
+	if hook.Secret == "" {
+		t.Errorf("Expected generated secret")
+	}
+
+	hooks, _ := webhookService.List()
+	if len(hooks) != 1 || hooks[0].Secret != "" {
+		t.Errorf("Expected redacted webhook, got %+v", hooks)
+	}
+
+	if err := webhookService.Delete(hook.ID); err != nil {
+		t.Errorf("Expected no error deleting: %v", err)
+	}
+	if err := webhookService.Delete(hook.ID); err != domain.ErrWebhookNotFound {
+		t.Errorf("Expected ErrWebhookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+)
+
+// EventService traces every ProcessEvent call on another
+// domain.EventService.
+type EventService struct {
+	next   domain.EventService
+	tracer trace.Tracer
+}
+
+func NewEventService(next domain.EventService, tp trace.TracerProvider) *EventService {
+	return &EventService{next: next, tracer: tp.Tracer(instrumentationName)}
+}
+
+func (s *EventService) ProcessEvent(ctx context.Context, event domain.EventRequest) (*domain.EventResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "EventService.ProcessEvent", trace.WithAttributes(
+		attribute.String("event.type", event.Type),
+		attribute.String("event.origin", event.Origin),
+		attribute.String("event.destination", event.Destination),
+		attribute.Int("event.amount", event.Amount),
+	))
+	defer span.End()
+
+	resp, err := s.next.ProcessEvent(ctx, event)
+	span.SetAttributes(attribute.String("event.outcome", domain.Outcome(err)))
+	endWithError(span, err)
+	return resp, err
+}
+
+// AccountRepository traces every operation on another
+// domain.AccountRepository.
+type AccountRepository struct {
+	next   domain.AccountRepository
+	tracer trace.Tracer
+}
+
+func NewAccountRepository(next domain.AccountRepository, tp trace.TracerProvider) *AccountRepository {
+	return &AccountRepository{next: next, tracer: tp.Tracer(instrumentationName)}
+}
+
+func (r *AccountRepository) FindByID(ctx context.Context, id string) (*domain.Account, error) {
+	ctx, span := r.tracer.Start(ctx, "AccountRepository.FindByID", trace.WithAttributes(
+		attribute.String("account.id", id),
+	))
+	defer span.End()
+
+	account, err := r.next.FindByID(ctx, id)
+	span.SetAttributes(attribute.Bool("account.found", account != nil))
+	endWithError(span, err)
+	return account, err
+}
+
+func (r *AccountRepository) Upsert(ctx context.Context, account *domain.Account) (*domain.Account, error) {
+	ctx, span := r.tracer.Start(ctx, "AccountRepository.Upsert", trace.WithAttributes(
+		attribute.String("account.id", account.ID),
+	))
+	defer span.End()
+
+	account, err := r.next.Upsert(ctx, account)
+	endWithError(span, err)
+	return account, err
+}
+
+func (r *AccountRepository) Reset(ctx context.Context) error {
+	ctx, span := r.tracer.Start(ctx, "AccountRepository.Reset")
+	defer span.End()
+
+	err := r.next.Reset(ctx)
+	endWithError(span, err)
+	return err
+}
+
+// Transaction records the accounts written inside the transaction, since the
+// operations on tx itself are not individually traced.
+func (r *AccountRepository) Transaction(ctx context.Context, fn func(tx domain.AccountTx) error) error {
+	ctx, span := r.tracer.Start(ctx, "AccountRepository.Transaction")
+	defer span.End()
+
+	var written []string
+	err := r.next.Transaction(ctx, func(tx domain.AccountTx) error {
+		return fn(&recordingTx{AccountTx: tx, written: &written})
+	})
+	span.SetAttributes(
+		attribute.StringSlice("account.ids", written),
+		attribute.Bool("transaction.committed", err == nil),
+	)
+	endWithError(span, err)
+	return err
+}
+
+type recordingTx struct {
+	domain.AccountTx
+	written *[]string
+}
+
+func (tx *recordingTx) Upsert(account *domain.Account) (*domain.Account, error) {
+	*tx.written = append(*tx.written, account.ID)
+	return tx.AccountTx.Upsert(account)
+}
+
+// endWithError marks the span as failed. Business rejections such as
+// insufficient funds are recorded as events rather than span errors, so
+// error rates reflect faults and timeouts only.
+func endWithError(span trace.Span, err error) {
+	switch domain.Outcome(err) {
+	case domain.OutcomeSuccess:
+	case domain.OutcomeNotFound, domain.OutcomeInsufficientFunds, domain.OutcomeFrozen,
+		domain.OutcomeBlocked, domain.OutcomeDenied, domain.OutcomeReview:
+		span.AddEvent("rejected", trace.WithAttributes(attribute.String("reason", err.Error())))
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
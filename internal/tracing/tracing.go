@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/logging"
+	"github.com/thihxm/ebanx-home-assignment/internal/respwriter"
+)
+
+const instrumentationName = "github.com/thihxm/ebanx-home-assignment"
+
+// NewProvider builds a tracer provider for the named exporter ("none" or
+// "stdout"), installs it and the W3C propagators globally, and returns a
+// function that flushes pending spans.
+func NewProvider(exporter string) (trace.TracerProvider, func(context.Context) error, error) {
+	var opts []sdktrace.TracerProviderOption
+	switch exporter {
+	case "none", "":
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, nil, fmt.Errorf("unsupported trace exporter %q", exporter)
+	}
+	opts = append(opts, sdktrace.WithResource(resource.NewSchemaless(
+		semconv.ServiceName("ipkiss"),
+	)))
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	return tp, tp.Shutdown, nil
+}
+
+// Middleware starts a server span per request, continuing any trace
+// propagated in the request headers. The span is named after the matched
+// route pattern.
+func Middleware(tp trace.TracerProvider, next http.Handler) http.Handler {
+	tracer := tp.Tracer(instrumentationName)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if id := logging.RequestID(ctx); id != "" {
+			span.SetAttributes(attribute.String("request.id", id))
+		}
+
+		rec := respwriter.Wrap(w)
+		inner := r.WithContext(ctx)
+		next.ServeHTTP(rec, inner)
+		// The mux records the pattern on the request it was given; copy it
+		// back so middleware further out, like the access log, sees it.
+		r.Pattern = inner.Pattern
+
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+			span.SetAttributes(semconv.HTTPRoute(r.Pattern))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.Status()))
+		if rec.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.Status()))
+		}
+	})
+}
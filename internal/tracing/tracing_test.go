@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/thihxm/ebanx-home-assignment/internal/domain"
+	"github.com/thihxm/ebanx-home-assignment/internal/repository"
+	"github.com/thihxm/ebanx-home-assignment/internal/service"
+)
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func attr(span *tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSpansShareTrace(t *testing.T) {
+	tp, exporter := newTestProvider()
+	repo := NewAccountRepository(repository.NewInMemoryRepository(), tp)
+	eventService := NewEventService(service.NewEventService(service.NewAccountService(repo)), tp)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /event", func(w http.ResponseWriter, r *http.Request) {
+		var event domain.EventRequest
+		json.NewDecoder(r.Body).Decode(&event)
+		eventService.ProcessEvent(r.Context(), event)
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := Middleware(tp, mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/event",
+		strings.NewReader(`{"type":"deposit","destination":"100","amount":10}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	server := findSpan(spans, "POST /event")
+	if server == nil {
+		t.Fatalf("Expected server span named after the route, got %d spans", len(spans))
+	}
+	if got := attr(server, "http.response.status_code").AsInt64(); got != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", got)
+	}
+
+	process := findSpan(spans, "EventService.ProcessEvent")
+	if process == nil {
+		t.Fatalf("Expected ProcessEvent span")
+	}
+	if process.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("Expected ProcessEvent to be a child of the server span")
+	}
+	if got := attr(process, "event.type").AsString(); got != "deposit" {
+		t.Errorf("Expected event.type deposit, got %q", got)
+	}
+	if got := attr(process, "event.outcome").AsString(); got != domain.OutcomeSuccess {
+		t.Errorf("Expected outcome success, got %q", got)
+	}
+
+	tx := findSpan(spans, "AccountRepository.Transaction")
+	if tx == nil {
+		t.Fatalf("Expected Transaction span")
+	}
+	if tx.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("Expected repository span in the request trace")
+	}
+	if got := attr(tx, "account.ids").AsStringSlice(); len(got) != 1 || got[0] != "100" {
+		t.Errorf("Expected account.ids [100], got %v", got)
+	}
+}
+
+func TestMiddlewareContinuesPropagatedTrace(t *testing.T) {
+	tp, exporter := newTestProvider()
+	handler := Middleware(tp, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// NewProvider installs the W3C propagator globally.
+	if _, _, err := NewProvider("none"); err != nil {
+		t.Fatalf("Expected no error creating provider: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected propagated trace ID, got %s", got)
+	}
+}
+
+func TestRejectionIsNotSpanError(t *testing.T) {
+	tp, exporter := newTestProvider()
+	eventService := NewEventService(service.NewEventService(
+		service.NewAccountService(repository.NewInMemoryRepository())), tp)
+
+	eventService.ProcessEvent(context.Background(), domain.EventRequest{Type: "withdraw", Origin: "100", Amount: 10})
+
+	span := findSpan(exporter.GetSpans(), "EventService.ProcessEvent")
+	if span == nil {
+		t.Fatalf("Expected ProcessEvent span")
+	}
+	if span.Status.Code == codes.Error {
+		t.Errorf("Expected not_found not to mark the span as failed")
+	}
+	if got := attr(span, "event.outcome").AsString(); got != domain.OutcomeNotFound {
+		t.Errorf("Expected outcome not_found, got %q", got)
+	}
+}
+
+func TestNewProviderRejectsUnknownExporter(t *testing.T) {
+	if _, _, err := NewProvider("jaeger"); err == nil {
+		t.Errorf("Expected error for unknown exporter")
+	}
+}